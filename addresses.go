@@ -0,0 +1,90 @@
+package multicall
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AddressInfo is the balance and code length of an address returned by AddressesData.
+type AddressInfo struct {
+	Address    common.Address
+	Balance    *big.Int
+	CodeLength *big.Int
+	IsContract bool // code length is not zero
+}
+
+// bigIntsResult replaces the decoded uint256[] of a successful result, one value per
+// address, by a []*big.Int.
+func bigIntsResult(result Result, addresses []*common.Address) Result {
+	if !result.Success {
+		return result
+	}
+
+	decoded, ok := result.Result.([]any)
+	if !ok || len(decoded) != 1 {
+		return Result{Success: false, Error: fmt.Errorf("unexpected result %T", result.Result), TxOrCall: result.TxOrCall}
+	}
+
+	values, err := toBigInts(decoded[0], len(addresses))
+	if err != nil {
+		return Result{Success: false, Error: err, TxOrCall: result.TxOrCall}
+	}
+	result.Result = values
+
+	return result
+}
+
+// addressesInfoResult replaces the decoded (uint256[], uint256[]) balances and code
+// lengths of a successful result by an []AddressInfo.
+func addressesInfoResult(result Result, addresses []*common.Address) Result {
+	if !result.Success {
+		return result
+	}
+
+	decoded, ok := result.Result.([]any)
+	if !ok || len(decoded) != 2 {
+		return Result{Success: false, Error: fmt.Errorf("unexpected result %T", result.Result), TxOrCall: result.TxOrCall}
+	}
+
+	balances, err := toBigInts(decoded[0], len(addresses))
+	if err != nil {
+		return Result{Success: false, Error: fmt.Errorf("error decoding balances: %w", err), TxOrCall: result.TxOrCall}
+	}
+	codeLengths, err := toBigInts(decoded[1], len(addresses))
+	if err != nil {
+		return Result{Success: false, Error: fmt.Errorf("error decoding code lengths: %w", err), TxOrCall: result.TxOrCall}
+	}
+
+	infos := make([]AddressInfo, len(addresses))
+	for i, address := range addresses {
+		infos[i] = AddressInfo{
+			Address:    *address,
+			Balance:    balances[i],
+			CodeLength: codeLengths[i],
+			IsContract: codeLengths[i].Sign() > 0,
+		}
+	}
+	result.Result = infos
+
+	return result
+}
+
+func toBigInts(decoded any, length int) ([]*big.Int, error) {
+	values, ok := decoded.([]any)
+	if !ok || len(values) != length {
+		return nil, fmt.Errorf("expected %d values, got %v", length, decoded)
+	}
+
+	integers := make([]*big.Int, length)
+	for i, value := range values {
+		integer, ok := value.(*big.Int)
+		if !ok {
+			return nil, fmt.Errorf("unexpected value %T at %d", value, i)
+		}
+		integers[i] = integer
+	}
+
+	return integers, nil
+}
@@ -0,0 +1,51 @@
+package multicall
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Backend is the set of node methods MultiCall relies on. Use NewBackend to adapt an
+// *ethclient.Client; simulated backends, custom RPC transports or instrumented wrappers
+// can be passed instead.
+type Backend interface {
+	ethereum.ChainIDReader
+	ethereum.BlockNumberReader
+	ethereum.ContractCaller
+	ethereum.GasEstimator
+	ethereum.GasPricer
+	ethereum.GasPricer1559
+	ethereum.TransactionSender
+	bind.DeployBackend
+
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+
+	// CallContext performs a raw JSON-RPC call. It is used for the deployless `eth_call`,
+	// which has no `to` and therefore cannot go through CallContract.
+	CallContext(ctx context.Context, result any, method string, args ...any) error
+}
+
+// EthClientBackend adapts an *ethclient.Client to Backend.
+type EthClientBackend struct {
+	*ethclient.Client
+}
+
+func NewBackend(client *ethclient.Client) Backend {
+	return &EthClientBackend{Client: client}
+}
+
+func (b *EthClientBackend) CallContext(ctx context.Context, result any, method string, args ...any) error {
+	return b.Client.Client().CallContext(ctx, result, method, args...)
+}
+
+func (b *EthClientBackend) BatchCallContext(ctx context.Context, batch []rpc.BatchElem) error {
+	return b.Client.Client().BatchCallContext(ctx, batch)
+}
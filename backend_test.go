@@ -0,0 +1,427 @@
+package multicall
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// echoCode is a contract returning its calldata: CALLDATASIZE 0 0 CALLDATACOPY CALLDATASIZE 0 RETURN.
+var echoCode = common.FromHex("0x365f5f37365ff3")
+
+// revertError mimics the JSON-RPC error returned by a node for a reverted call.
+type revertError struct {
+	data []byte
+}
+
+func (e *revertError) Error() string          { return "execution reverted" }
+func (e *revertError) ErrorCode() int         { return 3 }
+func (e *revertError) ErrorData() interface{} { return hexutil.Encode(e.data) }
+
+// rejectionError mimics the JSON-RPC error returned by a node rejecting a transaction.
+type rejectionError struct {
+	message string
+}
+
+func (e *rejectionError) Error() string  { return e.message }
+func (e *rejectionError) ErrorCode() int { return -32000 }
+
+// evmBackend is an in-memory Backend executing calls against a go-ethereum StateDB.
+type evmBackend struct {
+	mu           sync.Mutex
+	state        *state.StateDB
+	chainID      *big.Int
+	blockNumber  uint64
+	gasPrice     *big.Int
+	baseFee      *big.Int
+	sent         []*types.Transaction
+	nonces       map[common.Address]uint64
+	queued       map[common.Address]map[uint64]bool
+	stuck        int   // number of first sent transactions that are never mined
+	noOverrides  bool  // rejects state overrides, as nodes without their support
+	overridesErr error // returned by eth_calls with state overrides
+	lastCallArgs []any
+	checkNonces  bool // rejects nonces below the pending nonce of the sender, as nodes
+	failSends    int  // number of next sent transactions rejected
+	dropSends    int  // number of next sent transactions accepted, but answered with a transport error
+}
+
+func newEVMBackend(t *testing.T) *evmBackend {
+	t.Helper()
+
+	stateDB, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	if err != nil {
+		t.Fatalf("error creating state: %v", err)
+	}
+
+	return &evmBackend{
+		state:       stateDB,
+		chainID:     big.NewInt(1337),
+		blockNumber: 100,
+		gasPrice:    big.NewInt(1_000_000_000),
+		baseFee:     big.NewInt(7),
+		nonces:      map[common.Address]uint64{},
+		queued:      map[common.Address]map[uint64]bool{},
+	}
+}
+
+func (b *evmBackend) setCode(address common.Address, code []byte) {
+	b.state.SetCode(address, code)
+}
+
+func (b *evmBackend) setStorage(address common.Address, slot common.Hash, value common.Hash) {
+	b.state.SetState(address, slot, value)
+}
+
+func (b *evmBackend) setBalance(address common.Address, balance *big.Int) {
+	b.state.SetBalance(address, uint256.MustFromBig(balance), tracing.BalanceChangeUnspecified)
+}
+
+func (b *evmBackend) config(from common.Address, value *big.Int) *runtime.Config {
+	chainConfig := *params.MergedTestChainConfig
+	chainConfig.ChainID = b.chainID
+
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	return &runtime.Config{
+		ChainConfig: &chainConfig,
+		Origin:      from,
+		Value:       value,
+		GasLimit:    30_000_000,
+		GasPrice:    b.gasPrice,
+		BlockNumber: new(big.Int).SetUint64(b.blockNumber),
+		Time:        1_700_000_000,
+		BaseFee:     big.NewInt(7),
+		BlobBaseFee: big.NewInt(1),
+		Difficulty:  new(big.Int),
+		Random:      &common.Hash{},
+		State:       b.state.Copy(),
+	}
+}
+
+func (b *evmBackend) execute(msg ethereum.CallMsg) ([]byte, error) {
+	b.mu.Lock()
+	cfg := b.config(msg.From, msg.Value)
+	b.mu.Unlock()
+
+	var ret []byte
+	var err error
+	if msg.To == nil {
+		ret, _, _, err = runtime.Create(msg.Data, cfg)
+	} else {
+		ret, _, err = runtime.Call(*msg.To, msg.Data, cfg)
+	}
+	if errors.Is(err, vm.ErrExecutionReverted) {
+		return nil, &revertError{data: ret}
+	}
+
+	return ret, err
+}
+
+// executeWithOverride executes msg on a copy of the state with the given accounts overridden.
+func (b *evmBackend) executeWithOverride(msg ethereum.CallMsg, override StateOverride) ([]byte, error) {
+	b.mu.Lock()
+	original := b.state
+	b.state = original.Copy()
+	for address, account := range override {
+		if account.Code != nil {
+			b.state.SetCode(address, account.Code)
+		}
+		if account.Balance != nil {
+			b.state.SetBalance(address, uint256.MustFromBig(account.Balance), tracing.BalanceChangeUnspecified)
+		}
+		for key, value := range account.StateDiff {
+			b.state.SetState(address, key, value)
+		}
+	}
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		b.state = original
+		b.mu.Unlock()
+	}()
+
+	return b.execute(msg)
+}
+
+func (b *evmBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(b.chainID), ctx.Err()
+}
+
+func (b *evmBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	return b.blockNumber, ctx.Err()
+}
+
+func (b *evmBackend) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return b.execute(msg)
+}
+
+func (b *evmBackend) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	if _, err := b.execute(msg); err != nil {
+		return 0, err
+	}
+
+	return 100_000, ctx.Err()
+}
+
+func (b *evmBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(b.gasPrice), ctx.Err()
+}
+
+func (b *evmBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(2), ctx.Err()
+}
+
+func (b *evmBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{
+		Number:  new(big.Int).SetUint64(b.blockNumber),
+		BaseFee: b.baseFee,
+	}, ctx.Err()
+}
+
+func (b *evmBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failSends > 0 {
+		b.failSends--
+		return &rejectionError{message: "insufficient funds for gas * price + value"}
+	}
+	if b.checkNonces {
+		from, err := types.Sender(types.LatestSignerForChainID(b.chainID), tx)
+		if err != nil {
+			return err
+		}
+		if tx.Nonce() < b.nonces[from] {
+			return &rejectionError{message: fmt.Sprintf("nonce too low: next nonce %d, tx nonce %d", b.nonces[from], tx.Nonce())}
+		}
+		// future nonces are queued until the gap before them is filled
+		if b.queued[from] == nil {
+			b.queued[from] = map[uint64]bool{}
+		}
+		b.queued[from][tx.Nonce()] = true
+		for b.queued[from][b.nonces[from]] {
+			delete(b.queued[from], b.nonces[from])
+			b.nonces[from]++
+		}
+	}
+
+	b.sent = append(b.sent, tx)
+	if b.dropSends > 0 {
+		b.dropSends--
+		return errors.New("connection reset by peer")
+	}
+
+	return ctx.Err()
+}
+
+func (b *evmBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, tx := range b.sent {
+		if tx.Hash() == txHash && i >= b.stuck {
+			return &types.Receipt{
+				Status:      types.ReceiptStatusSuccessful,
+				TxHash:      txHash,
+				BlockNumber: new(big.Int).SetUint64(b.blockNumber),
+			}, nil
+		}
+	}
+
+	return nil, ethereum.NotFound
+}
+
+func (b *evmBackend) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return b.state.GetCode(account), ctx.Err()
+}
+
+func (b *evmBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.nonces[account], ctx.Err()
+}
+
+func (b *evmBackend) CallContext(ctx context.Context, result any, method string, args ...any) error {
+	if method == "eth_getStorageAt" {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		*result.(*common.Hash) = b.state.GetState(args[0].(common.Address), args[1].(common.Hash))
+
+		return ctx.Err()
+	}
+	if method != "eth_call" {
+		return fmt.Errorf("method %s not supported", method)
+	}
+
+	params := args[0].(map[string]interface{})
+	msg := ethereum.CallMsg{Data: common.FromHex(params["data"].(string))}
+	if to, ok := params["to"].(*common.Address); ok {
+		msg.To = to
+	}
+	b.mu.Lock()
+	b.lastCallArgs = args
+	b.mu.Unlock()
+
+	var ret []byte
+	var err error
+	if len(args) > 2 && args[2] != nil && b.noOverrides {
+		return fmt.Errorf("too many arguments, want at most 2")
+	} else if len(args) > 2 && args[2] != nil && b.overridesErr != nil {
+		return b.overridesErr
+	} else if len(args) > 2 && args[2] != nil {
+		ret, err = b.executeWithOverride(msg, args[2].(StateOverride))
+	} else {
+		ret, err = b.execute(msg)
+	}
+	if err != nil {
+		return err
+	}
+
+	*result.(*string) = hexutil.Encode(ret)
+
+	return ctx.Err()
+}
+
+func TestNewMultiCallFallsBackToDeployless(t *testing.T) {
+	backend := newEVMBackend(t)
+
+	mcall, err := NewMultiCall(GENERAL, backend, nil)
+	if err != nil {
+		t.Fatalf("error creating multicall: %v", err)
+	}
+
+	if mcall.MultiCallType != DEPLOYLESS {
+		t.Errorf("expected multicall type %d, got %d", DEPLOYLESS, mcall.MultiCallType)
+	}
+}
+
+func TestNewMultiCallFallbacks(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		multiCallType MultiCallType
+		omnesDeployed bool
+		expectedType  MultiCallType
+		expectedWrite *common.Address
+	}{
+		// used to dereference the nil write address
+		{"omnes without code", OMNES, false, DEPLOYLESS, nil},
+		{"omnes", OMNES, true, OMNES, &OMNES_MULTICALL_ADDRESS},
+		// used to select OMNES with a nil write address
+		{"general without code", GENERAL, false, DEPLOYLESS, nil},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			backend := newEVMBackend(t)
+			if test.omnesDeployed {
+				backend.setCode(OMNES_MULTICALL_ADDRESS, []byte{0x00})
+			}
+
+			mcall, err := NewMultiCall(test.multiCallType, backend, nil)
+			if err != nil {
+				t.Fatalf("error creating multicall: %v", err)
+			}
+
+			if mcall.MultiCallType != test.expectedType {
+				t.Errorf("expected multicall type %d, got %d", test.expectedType, mcall.MultiCallType)
+			}
+			if (mcall.WriteAddress == nil) != (test.expectedWrite == nil) ||
+				(test.expectedWrite != nil && *mcall.WriteAddress != *test.expectedWrite) {
+				t.Errorf("expected write address %v, got %v", test.expectedWrite, mcall.WriteAddress)
+			}
+		})
+	}
+}
+
+func TestSimulateCallWithBackend(t *testing.T) {
+	backend := newEVMBackend(t)
+	target := common.HexToAddress("0x4444")
+	backend.setCode(target, echoCode)
+
+	mcall, err := NewMultiCall(GENERAL, backend, nil)
+	if err != nil {
+		t.Fatalf("error creating multicall: %v", err)
+	}
+
+	calls := NewCalls(
+		[]common.Address{target, target}, []string{"", ""}, nil, [][]byte{{0xab}, {0xcd}}, nil, nil,
+	)
+
+	result := mcall.SimulateCall(calls, backend, nil)
+	if !result.Success {
+		t.Fatalf("simulation failed: %v", result.Error)
+	}
+
+	simulation := result.Result.(Simulation)
+	if len(simulation.Results) != 2 || !simulation.AllSucceeded() {
+		t.Fatalf("expected 2 successful simulated calls, got %+v", simulation)
+	}
+	if !bytes.Equal(simulation.Results[1].ReturnData, []byte{0xcd}) {
+		t.Errorf("expected return data cd, got %x", simulation.Results[1].ReturnData)
+	}
+
+	totalGasUsed := new(big.Int).Add(simulation.Results[0].GasUsed, simulation.Results[1].GasUsed)
+	if totalGasUsed.Sign() <= 0 || totalGasUsed.Cmp(simulation.TotalGasUsed) != 0 {
+		t.Errorf("expected total gas used %s, got %s", totalGasUsed, simulation.TotalGasUsed)
+	}
+}
+
+func TestSimulateCallWithStateOverride(t *testing.T) {
+	backend := newEVMBackend(t)
+	target := common.HexToAddress("0x4444")
+
+	mcall, err := NewMultiCall(GENERAL, backend, nil)
+	if err != nil {
+		t.Fatalf("error creating multicall: %v", err)
+	}
+	mcall = mcall.WithOverrides(&CallOverrides{State: StateOverride{target: {Code: echoCode}}})
+
+	calls := NewCalls([]common.Address{target}, []string{""}, nil, [][]byte{{0xcd}}, nil, nil)
+	result := mcall.SimulateCall(calls, backend, big.NewInt(42))
+	if !result.Success {
+		t.Fatalf("simulation failed: %v", result.Error)
+	}
+
+	if returnData := result.Result.(Simulation).Results[0].ReturnData; !bytes.Equal(returnData, []byte{0xcd}) {
+		t.Errorf("expected return data of the overridden code cd, got %x", returnData)
+	}
+	if backend.lastCallArgs[1] != "0x2a" {
+		t.Errorf("expected hex block number 0x2a, got %v", backend.lastCallArgs[1])
+	}
+	if len(backend.state.GetCode(target)) != 0 {
+		t.Errorf("expected override not to change the state")
+	}
+}
+
+func TestContextCancellation(t *testing.T) {
+	backend := newEVMBackend(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewMultiCallContext(ctx, GENERAL, backend, nil); err == nil {
+		t.Errorf("expected error from cancelled context")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -12,13 +13,13 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
 // readContract makes a call to a contract and returns the returned bytecode.
 func readContract(
-	client *ethclient.Client, from, to *common.Address, encodedCall []byte, blockNumber *big.Int,
+	ctx context.Context, client Backend, from, to *common.Address, encodedCall []byte, blockNumber *big.Int,
+	overrides *CallOverrides,
 ) ([]byte, *ethereum.CallMsg, error) {
 	if from == nil {
 		from = &ZERO_ADDRESS
@@ -30,10 +31,24 @@ func readContract(
 		Data: encodedCall,
 	}
 
-	result, err := client.CallContract(context.Background(),
-		call,
-		blockNumber,
-	)
+	var result []byte
+	var err error
+	if overrides == nil {
+		result, err = client.CallContract(ctx,
+			call,
+			blockNumber,
+		)
+	} else {
+		var rawResult string
+		rawResult, err = ethCall(ctx, client, map[string]interface{}{
+			"from": call.From,
+			"to":   call.To,
+			"data": hexutil.Encode(encodedCall),
+		}, blockNumber, overrides)
+		if err == nil {
+			result, err = hexutil.Decode(rawResult)
+		}
+	}
 	if err != nil {
 		return nil, nil, fmt.Errorf("error reading contract: %w, with data: %s", err, common.Bytes2Hex(encodedCall))
 	}
@@ -41,57 +56,204 @@ func readContract(
 	return result, &call, nil
 }
 
-// createTransaction creates a new transaction object.
+// ethCall makes a raw eth_call, appending the state and block overrides to its parameters.
+func ethCall(
+	ctx context.Context, client Backend, callArgs map[string]interface{}, blockNumber *big.Int,
+	overrides *CallOverrides,
+) (string, error) {
+	blockNumberArg := "latest"
+	if blockNumber != nil {
+		blockNumberArg = hexutil.EncodeBig(blockNumber)
+	}
+
+	args := []any{callArgs, blockNumberArg}
+	if overrides != nil && overrides.Block != nil {
+		args = append(args, overrides.State, overrides.Block)
+	} else if overrides != nil && overrides.State != nil {
+		args = append(args, overrides.State)
+	}
+
+	var rawResponse string
+	err := client.CallContext(ctx, &rawResponse, "eth_call", args...)
+
+	return rawResponse, err
+}
+
+// isOverridesRejected reports whether the node rejected the overrides of an eth_call, as
+// nodes without their support answer with invalid params instead of running the call.
+func isOverridesRejected(err error) bool {
+	if _, reverted := parseRevertData(err); reverted {
+		return false
+	}
+
+	var ec rpc.Error
+	if errors.As(err, &ec) && ec.ErrorCode() == -32602 {
+		return true
+	}
+	message := strings.ToLower(err.Error())
+
+	return strings.Contains(message, "too many arguments") || strings.Contains(message, "override")
+}
+
+// createTransaction creates a new transaction object of the type selected in opts.
 func createTransaction(
-	client *ethclient.Client,
+	ctx context.Context,
+	client Backend,
+	chainId *big.Int,
 	from *common.Address,
 	to *common.Address,
 	msgValue *big.Int,
 	callData []byte,
+	opts *TxOptions,
 ) (*types.Transaction, error) {
-	gasPrice, err := client.SuggestGasPrice(context.Background())
+	if opts == nil {
+		opts = &TxOptions{}
+	}
+
+	txType, baseFee, err := resolveTxType(ctx, client, opts.Type)
 	if err != nil {
 		return nil, err
 	}
 
-	gasLimit, err := client.EstimateGas(
-		context.Background(),
-		ethereum.CallMsg{
-			From: *from, // the sender of the 'transaction'
-			To:   to,    // the destination contract (nil for contract creation)
-			Gas:  0,     // if 0, the call executes with near-infinite gas
-			// GasPrice:  gasPrice        // wei <-> gas exchange ratio
-			GasFeeCap: gasPrice, // EIP-1559 fee cap per gas.
-			GasTipCap: gasPrice, // EIP-1559 tip per gas.
-			Value:     msgValue,
-			Data:      callData,
-		},
-	)
+	msg := ethereum.CallMsg{
+		From:       *from, // the sender of the 'transaction'
+		To:         to,    // the destination contract (nil for contract creation)
+		Gas:        0,     // if 0, the call executes with near-infinite gas
+		Value:      msgValue,
+		Data:       callData,
+		AccessList: opts.AccessList,
+	}
 
-	if err != nil {
-		return nil, err
+	var gasPrice, gasTipCap, gasFeeCap *big.Int
+	if txType == LEGACY_TX {
+		gasPrice = opts.GasPrice
+		if gasPrice == nil {
+			gasPrice, err = client.SuggestGasPrice(ctx)
+			if err != nil {
+				return nil, err
+			}
+		}
+		msg.GasPrice = gasPrice // wei <-> gas exchange ratio
+	} else {
+		gasTipCap = opts.GasTipCap
+		if gasTipCap == nil {
+			gasTipCap, err = client.SuggestGasTipCap(ctx)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		gasFeeCap = opts.GasFeeCap
+		if gasFeeCap == nil {
+			// leaves room for the base fee to double before the transaction is included
+			gasFeeCap = new(big.Int).Add(gasTipCap, new(big.Int).Mul(baseFee, big.NewInt(2)))
+		}
+		if gasTipCap.Cmp(gasFeeCap) > 0 {
+			gasTipCap = gasFeeCap
+		}
+		msg.GasFeeCap = gasFeeCap // EIP-1559 fee cap per gas.
+		msg.GasTipCap = gasTipCap // EIP-1559 tip per gas.
+	}
+
+	gasLimit := opts.GasLimit
+	if gasLimit == 0 {
+		gasLimit, err = client.EstimateGas(ctx, msg)
+		if err != nil {
+			return nil, err
+		}
+
+		if opts.GasLimitMultiplier > 0 {
+			gasLimit = uint64(float64(gasLimit) * opts.GasLimitMultiplier)
+		}
+	}
+
+	var nonce uint64
+	if opts.Nonce != nil {
+		nonce = *opts.Nonce
+	} else if opts.NonceManager != nil {
+		nonce, err = opts.NonceManager.Next(ctx, client, *from)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		nonce, err = client.PendingNonceAt(ctx, *from)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	nonce, err := client.PendingNonceAt(context.Background(), *from)
+	if txType == LEGACY_TX && len(opts.AccessList) > 0 {
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    chainId,
+			Nonce:      nonce,
+			GasPrice:   gasPrice,
+			Gas:        gasLimit,
+			To:         to,
+			Value:      msgValue,
+			Data:       callData,
+			AccessList: opts.AccessList,
+		}), nil
+	} else if txType == LEGACY_TX {
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: gasPrice,
+			Gas:      gasLimit,
+			To:       to,
+			Value:    msgValue,
+			Data:     callData,
+		}), nil
+	}
+
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:    chainId,
+		Nonce:      nonce,
+		GasTipCap:  gasTipCap,
+		GasFeeCap:  gasFeeCap,
+		Gas:        gasLimit,
+		To:         to,
+		Value:      msgValue,
+		Data:       callData,
+		AccessList: opts.AccessList,
+	}), nil
+}
+
+// resolveTxType returns the transaction type to build and, for dynamic fee transactions,
+// the base fee of the latest block.
+func resolveTxType(ctx context.Context, client Backend, txType TxType) (TxType, *big.Int, error) {
+	if txType == LEGACY_TX {
+		return LEGACY_TX, nil, nil
+	}
+
+	header, err := client.HeaderByNumber(ctx, nil)
 	if err != nil {
-		return nil, err
+		return 0, nil, fmt.Errorf("error getting latest header: %w", err)
+	}
+
+	if header.BaseFee == nil {
+		if txType == DYNAMIC_FEE_TX {
+			return 0, nil, fmt.Errorf("chain does not support dynamic fee transactions")
+		}
+		return LEGACY_TX, nil, nil
 	}
 
-	return types.NewTransaction(nonce, *to, msgValue, gasLimit, gasPrice, nil), nil
+	return DYNAMIC_FEE_TX, header.BaseFee, nil
 }
 
 // sendSignedTransaction sends a signed transaction
-func sendSignedTransaction(client *ethclient.Client, tx *types.Transaction) (*types.Receipt, error) {
-	err := client.SendTransaction(context.Background(), tx)
+func sendSignedTransaction(ctx context.Context, client Backend, tx *types.Transaction) (*types.Receipt, error) {
+	err := client.SendTransaction(ctx, tx)
 	if err != nil {
 		return nil, fmt.Errorf("error sending transaction (txHash=%v): %v", tx.Hash(), err)
 	}
 
-	// @note implement retry to bump gas
-	d := time.Now().Add(MINING_WAIT_DURATION)
-	ctx, cancel := context.WithDeadline(context.Background(), d)
+	return waitMined(ctx, client, tx)
+}
+
+// waitMined waits for the receipt of a sent transaction.
+func waitMined(ctx context.Context, client Backend, tx *types.Transaction) (*types.Receipt, error) {
+	waitCtx, cancel := context.WithTimeout(ctx, MINING_WAIT_DURATION)
 	defer cancel()
-	receipt, err := bind.WaitMined(ctx, client, tx)
+	receipt, err := bind.WaitMined(waitCtx, client, tx)
 	if err != nil {
 		return nil, fmt.Errorf("error while waiting for receipt (txHash=%v): %v", tx.Hash(), err)
 	}
@@ -99,6 +261,186 @@ func sendSignedTransaction(client *ethclient.Client, tx *types.Transaction) (*ty
 	return receipt, nil
 }
 
+// waitWithReplacement waits for the sent transaction and, while none of the sent
+// transactions is mined within the policy window, re-signs it with the same nonce and
+// bumped fees. It returns the receipt and transaction that landed, and the hashes of the
+// other ones.
+func waitWithReplacement(
+	ctx context.Context,
+	client Backend,
+	signer SignerInterface,
+	chainId *big.Int,
+	tx *types.Transaction,
+	policy *ReplacementPolicy,
+) (*types.Receipt, *types.Transaction, []common.Hash, error) {
+	waitCtx, cancel := context.WithTimeout(ctx, MINING_WAIT_DURATION)
+	defer cancel()
+
+	sent := []*types.Transaction{tx}
+	last := tx
+	attempts := 0
+	for {
+		receipt, landed, err := waitForAny(waitCtx, client, sent, policy, attempts < policy.MaxAttempts)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("error while waiting for receipt (txHash=%v): %v", last.Hash(), err)
+		}
+		if receipt != nil {
+			var replaced []common.Hash
+			for _, sentTx := range sent {
+				if sentTx.Hash() != landed.Hash() {
+					replaced = append(replaced, sentTx.Hash())
+				}
+			}
+
+			return receipt, landed, replaced, nil
+		}
+
+		attempts++
+		bumped, err := bumpTransaction(last, policy.BumpPercent)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if policy.MaxGasPrice != nil && bumped.GasFeeCap().Cmp(policy.MaxGasPrice) > 0 {
+			// keeps waiting for the transactions already sent
+			attempts = policy.MaxAttempts
+			continue
+		}
+
+		signedTx, err := signer.SignTx(bumped, chainId)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("error signing replacement transaction: %w", err)
+		}
+		last = signedTx
+
+		// a rejected replacement, e.g. because a previous transaction was just mined,
+		// is not fatal: the next attempt bumps the fees again
+		if err := client.SendTransaction(waitCtx, signedTx); err == nil {
+			sent = append(sent, signedTx)
+		}
+	}
+}
+
+// waitForAny polls the receipts of the given transactions until one of them is mined. When
+// canReplace is true it returns a nil receipt once the policy window has elapsed.
+func waitForAny(
+	ctx context.Context, client Backend, txs []*types.Transaction, policy *ReplacementPolicy, canReplace bool,
+) (*types.Receipt, *types.Transaction, error) {
+	pollInterval := policy.PollInterval
+	if pollInterval == 0 {
+		pollInterval = DEFAULT_RECEIPT_POLL_INTERVAL
+	}
+
+	var startBlock uint64
+	if policy.Blocks > 0 {
+		var err error
+		startBlock, err = client.BlockNumber(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	deadline := time.Now().Add(policy.Timeout)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		for _, tx := range txs {
+			receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+			if err == nil && receipt != nil {
+				return receipt, tx, nil
+			}
+			if err != nil && !errors.Is(err, ethereum.NotFound) {
+				return nil, nil, err
+			}
+		}
+
+		if canReplace {
+			if policy.Blocks > 0 {
+				blockNumber, err := client.BlockNumber(ctx)
+				if err != nil {
+					return nil, nil, err
+				}
+				if blockNumber >= startBlock+policy.Blocks {
+					return nil, nil, nil
+				}
+			} else if !time.Now().Before(deadline) {
+				return nil, nil, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// bumpTransaction returns an unsigned copy of tx with its fees increased by the given
+// percentage, raised to the minimum accepted by nodes for a replacement.
+func bumpTransaction(tx *types.Transaction, percent float64) (*types.Transaction, error) {
+	percent = max(percent, MIN_LEGACY_PRICE_BUMP)
+	if tx.Type() == types.DynamicFeeTxType {
+		percent = max(percent, MIN_DYNAMIC_FEE_PRICE_BUMP)
+	}
+
+	return copyTransaction(tx, tx.Nonce(), bumpFee(tx.GasTipCap(), percent), bumpFee(tx.GasFeeCap(), percent))
+}
+
+// copyTransaction returns an unsigned copy of tx with the given nonce and fees. The fee
+// cap is the gas price of legacy and access list transactions, which ignore the tip.
+func copyTransaction(tx *types.Transaction, nonce uint64, gasTipCap *big.Int, gasFeeCap *big.Int) (*types.Transaction, error) {
+	switch tx.Type() {
+	case types.LegacyTxType:
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: gasFeeCap,
+			Gas:      tx.Gas(),
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		}), nil
+	case types.AccessListTxType:
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      nonce,
+			GasPrice:   gasFeeCap,
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}), nil
+	case types.DynamicFeeTxType:
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      nonce,
+			GasTipCap:  gasTipCap,
+			GasFeeCap:  gasFeeCap,
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}), nil
+	}
+
+	return nil, fmt.Errorf("cannot copy transaction of type %d", tx.Type())
+}
+
+// bumpFee increases fee by percent, rounding up so the increase is never below it.
+func bumpFee(fee *big.Int, percent float64) *big.Int {
+	const precision = 100_000 // thousandths of a percent
+
+	bumped := new(big.Int).Mul(fee, big.NewInt(precision+int64(percent*precision/100)))
+	bumped.Add(bumped, big.NewInt(precision-1))
+	bumped.Div(bumped, big.NewInt(precision))
+	if bumped.Cmp(fee) <= 0 {
+		bumped.Add(fee, common.Big1)
+	}
+
+	return bumped
+}
+
 func parseRevertData(err error) ([]byte, bool) {
 
 	var ec rpc.Error
@@ -0,0 +1,209 @@
+package multicall
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const testPrivateKey = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+
+func TestCreateTransactionTypes(t *testing.T) {
+	backend := newEVMBackend(t)
+	from := common.HexToAddress("0x1111")
+	to := common.HexToAddress("0x4444")
+	backend.setCode(to, echoCode)
+	callData := []byte{0xde, 0xad}
+
+	tests := []struct {
+		name     string
+		opts     *TxOptions
+		baseFee  *big.Int
+		expected uint8
+	}{
+		{"auto on london chain", nil, big.NewInt(7), types.DynamicFeeTxType},
+		{"auto on legacy chain", nil, nil, types.LegacyTxType},
+		{"forced legacy", &TxOptions{Type: LEGACY_TX}, big.NewInt(7), types.LegacyTxType},
+		{"forced dynamic fee", &TxOptions{Type: DYNAMIC_FEE_TX}, big.NewInt(7), types.DynamicFeeTxType},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backend.baseFee = test.baseFee
+
+			tx, err := createTransaction(
+				context.Background(), backend, backend.chainID, &from, &to, big.NewInt(0), callData, test.opts,
+			)
+			if err != nil {
+				t.Fatalf("error creating transaction: %v", err)
+			}
+
+			if tx.Type() != test.expected {
+				t.Errorf("expected tx type %d, got %d", test.expected, tx.Type())
+			}
+			if common.Bytes2Hex(tx.Data()) != "dead" {
+				t.Errorf("expected call data dead, got %x", tx.Data())
+			}
+			if tx.Type() == types.DynamicFeeTxType && tx.GasFeeCap().Cmp(big.NewInt(2+2*7)) != 0 {
+				t.Errorf("unexpected fee cap %s", tx.GasFeeCap())
+			}
+		})
+	}
+}
+
+func TestCreateTransactionDynamicFeeWithoutBaseFee(t *testing.T) {
+	backend := newEVMBackend(t)
+	backend.baseFee = nil
+	from := common.HexToAddress("0x1111")
+	to := common.HexToAddress("0x4444")
+
+	_, err := createTransaction(
+		context.Background(), backend, backend.chainID, &from, &to, nil, nil, &TxOptions{Type: DYNAMIC_FEE_TX},
+	)
+	if err == nil {
+		t.Errorf("expected error for dynamic fee transaction on a chain without base fee")
+	}
+}
+
+func TestGenericSignerSignsDynamicFeeTx(t *testing.T) {
+	signer, err := NewSigner(testPrivateKey)
+	if err != nil {
+		t.Fatalf("error creating signer: %v", err)
+	}
+
+	chainId := big.NewInt(1337)
+	to := common.HexToAddress("0x4444")
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID: chainId, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10), Gas: 21000, To: &to,
+	})
+
+	signedTx, err := signer.SignTx(tx, chainId)
+	if err != nil {
+		t.Fatalf("error signing transaction: %v", err)
+	}
+
+	sender, err := types.Sender(types.LatestSignerForChainID(chainId), signedTx)
+	if err != nil {
+		t.Fatalf("error recovering sender: %v", err)
+	}
+	if sender != *signer.GetAddress() {
+		t.Errorf("expected sender %s, got %s", signer.GetAddress(), sender)
+	}
+}
+
+func TestCreateTransactionOptions(t *testing.T) {
+	backend := newEVMBackend(t)
+	from := common.HexToAddress("0x1111")
+	to := common.HexToAddress("0x4444")
+	nonce := uint64(42)
+	accessList := types.AccessList{{Address: to, StorageKeys: []common.Hash{{0x01}}}}
+
+	tx, err := createTransaction(
+		context.Background(), backend, backend.chainID, &from, &to, nil, nil,
+		&TxOptions{
+			Type:       DYNAMIC_FEE_TX,
+			GasFeeCap:  big.NewInt(100),
+			GasTipCap:  big.NewInt(300),
+			Nonce:      &nonce,
+			AccessList: accessList,
+		},
+	)
+	if err != nil {
+		t.Fatalf("error creating transaction: %v", err)
+	}
+
+	if tx.Nonce() != nonce {
+		t.Errorf("expected nonce %d, got %d", nonce, tx.Nonce())
+	}
+	if tx.GasTipCap().Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("expected tip capped to 100, got %s", tx.GasTipCap())
+	}
+	if len(tx.AccessList()) != 1 {
+		t.Errorf("expected access list to be set, got %v", tx.AccessList())
+	}
+
+	tx, err = createTransaction(
+		context.Background(), backend, backend.chainID, &from, &to, nil, nil,
+		&TxOptions{Type: LEGACY_TX, GasPrice: big.NewInt(5), GasLimitMultiplier: 1.5, AccessList: accessList},
+	)
+	if err != nil {
+		t.Fatalf("error creating transaction: %v", err)
+	}
+
+	if tx.Type() != types.AccessListTxType {
+		t.Errorf("expected access list tx, got type %d", tx.Type())
+	}
+	if tx.GasPrice().Cmp(big.NewInt(5)) != 0 {
+		t.Errorf("expected gas price 5, got %s", tx.GasPrice())
+	}
+	if tx.Gas() != 150_000 {
+		t.Errorf("expected gas limit 150000, got %d", tx.Gas())
+	}
+}
+
+func TestBumpTransaction(t *testing.T) {
+	to := common.HexToAddress("0x4444")
+
+	legacy, err := bumpTransaction(types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(1000), To: &to}), 5)
+	if err != nil {
+		t.Fatalf("error bumping legacy transaction: %v", err)
+	}
+	if legacy.GasPrice().Int64() != 1100 {
+		t.Errorf("expected gas price 1100, got %s", legacy.GasPrice())
+	}
+
+	dynamic, err := bumpTransaction(types.NewTx(&types.DynamicFeeTx{
+		ChainID: big.NewInt(1337), GasTipCap: big.NewInt(3), GasFeeCap: big.NewInt(1000), To: &to,
+	}), 0)
+	if err != nil {
+		t.Fatalf("error bumping dynamic fee transaction: %v", err)
+	}
+	if dynamic.GasFeeCap().Int64() != 1125 || dynamic.GasTipCap().Int64() != 4 {
+		t.Errorf("expected fee cap 1125 and tip 4, got %s and %s", dynamic.GasFeeCap(), dynamic.GasTipCap())
+	}
+}
+
+func TestAggregateCallsWithReplacement(t *testing.T) {
+	backend := newEVMBackend(t)
+	backend.stuck = 1
+	mcall := newTestWriteMultiCall(t, backend)
+	nonce := uint64(7)
+
+	result := mcall.AggregateCalls(newRawCalls([]byte{0x01}), backend, nil, false, &TxOptions{
+		Type:        LEGACY_TX,
+		GasPrice:    big.NewInt(1000),
+		GasLimit:    60_000,
+		Nonce:       &nonce,
+		Replacement: &ReplacementPolicy{Timeout: 10 * time.Millisecond, MaxAttempts: 3, PollInterval: time.Millisecond},
+	})
+	if !result.Success {
+		t.Fatalf("write failed: %v", result.Error)
+	}
+
+	if len(backend.sent) != 2 {
+		t.Fatalf("expected 2 sent transactions, got %d", len(backend.sent))
+	}
+	landed := backend.sent[1]
+	if result.TxOrCall.Nonce != 7 || result.TxOrCall.GasPrice.Int64() != 1100 || landed.GasPrice().Int64() != 1100 {
+		t.Errorf("expected the replacement with nonce 7 and gas price 1100, got %s", result.TxOrCall.String())
+	}
+	replaced := result.TxOrCall.ReplacedHashes
+	if len(replaced) != 1 || replaced[0] != backend.sent[0].Hash() {
+		t.Errorf("expected replaced hashes [%s], got %v", backend.sent[0].Hash(), replaced)
+	}
+
+	// a policy without a timeout or a number of blocks would replace at every poll
+	result = mcall.AggregateCalls(newRawCalls([]byte{0x01}), backend, nil, false, &TxOptions{
+		Type:        LEGACY_TX,
+		GasPrice:    big.NewInt(1000),
+		GasLimit:    60_000,
+		Replacement: &ReplacementPolicy{MaxAttempts: 3},
+	})
+	if result.Success || len(backend.sent) != 2 {
+		t.Errorf("expected the zero-value policy to be rejected before sending, got %d sent (%v)", len(backend.sent), result.Error)
+	}
+}
@@ -0,0 +1,96 @@
+package multicall
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CHAIN_DATA_RETURN_TYPES are the return types of getChainData() and of the deployless
+// CHAIN_DATA call, in the order of the ChainInfo fields.
+var CHAIN_DATA_RETURN_TYPES = []string{
+	"uint256",
+	"uint256",
+	"bytes32",
+	"uint256",
+	"address",
+	"uint256",
+	"uint256",
+	"uint256",
+	"uint256",
+}
+
+// ChainInfo is the chain and block data returned by ChainData.
+type ChainInfo struct {
+	ChainID     *big.Int
+	BlockNumber *big.Int
+	BlockHash   common.Hash // blockhash(block.number), zero as the EVM only exposes past hashes
+	BaseFee     *big.Int
+	Coinbase    common.Address
+	Timestamp   *big.Int
+	PrevRandao  *big.Int // block difficulty before the merge
+	GasLimit    *big.Int
+	GasPrice    *big.Int
+}
+
+// newChainInfo converts values decoded with CHAIN_DATA_RETURN_TYPES.
+func newChainInfo(decoded []any) (ChainInfo, error) {
+	if len(decoded) != len(CHAIN_DATA_RETURN_TYPES) {
+		return ChainInfo{}, fmt.Errorf("expected %d chain data values, got %d", len(CHAIN_DATA_RETURN_TYPES), len(decoded))
+	}
+
+	integers := make([]*big.Int, len(decoded))
+	for i, value := range decoded {
+		if CHAIN_DATA_RETURN_TYPES[i] != "uint256" {
+			continue
+		}
+
+		integer, ok := value.(*big.Int)
+		if !ok {
+			return ChainInfo{}, fmt.Errorf("unexpected chain data value %T at %d", value, i)
+		}
+		integers[i] = integer
+	}
+
+	blockHash, ok := decoded[2].([]byte)
+	if !ok {
+		return ChainInfo{}, fmt.Errorf("unexpected block hash %T", decoded[2])
+	}
+	coinbase, ok := decoded[4].(string)
+	if !ok {
+		return ChainInfo{}, fmt.Errorf("unexpected coinbase %T", decoded[4])
+	}
+
+	return ChainInfo{
+		ChainID:     integers[0],
+		BlockNumber: integers[1],
+		BlockHash:   common.BytesToHash(blockHash),
+		BaseFee:     integers[3],
+		Coinbase:    common.HexToAddress(coinbase),
+		Timestamp:   integers[5],
+		PrevRandao:  integers[6],
+		GasLimit:    integers[7],
+		GasPrice:    integers[8],
+	}, nil
+}
+
+// chainInfoResult replaces the decoded chain data of a successful result by a ChainInfo.
+func chainInfoResult(result Result) Result {
+	if !result.Success {
+		return result
+	}
+
+	decoded, ok := result.Result.([]any)
+	if !ok {
+		return Result{Success: false, Error: fmt.Errorf("unexpected chain data %T", result.Result), TxOrCall: result.TxOrCall}
+	}
+
+	chainInfo, err := newChainInfo(decoded)
+	if err != nil {
+		return Result{Success: false, Error: err, TxOrCall: result.TxOrCall}
+	}
+	result.Result = chainInfo
+
+	return result
+}
@@ -0,0 +1,262 @@
+package multicall
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// ChunkOptions configures how a batch of calls is split into several aggregate calls.
+// A zero limit is ignored; with every limit at zero the batch is sent as a single chunk.
+type ChunkOptions struct {
+	MaxCalls         int    // maximum number of calls per chunk
+	MaxCallDataBytes int    // maximum summed call data size per chunk
+	MaxGas           uint64 // maximum estimated gas per chunk
+	CallGas          uint64 // estimated execution gas of each call, DEFAULT_CALL_GAS if zero
+	Concurrency      int    // number of chunks executed in parallel, 1 if zero
+}
+
+// Chunk splits the calls according to the given options, preserving their order.
+func (c Calls) Chunk(opts ChunkOptions) ([]Calls, error) {
+	bounds, err := chunkBounds(c, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make([]Calls, len(bounds))
+	for i, bound := range bounds {
+		chunks[i] = c[bound[0]:bound[1]]
+	}
+
+	return chunks, nil
+}
+
+// Chunk splits the calls according to the given options, preserving their order.
+func (c CallsWithFailure) Chunk(opts ChunkOptions) ([]CallsWithFailure, error) {
+	bounds, err := chunkBounds(c, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make([]CallsWithFailure, len(bounds))
+	for i, bound := range bounds {
+		chunks[i] = c[bound[0]:bound[1]]
+	}
+
+	return chunks, nil
+}
+
+func (m *MultiCall) AggregateStaticChunked(
+	calls []Call, client Backend, blockNumber *big.Int, opts ChunkOptions,
+) Result {
+	return m.AggregateStaticChunkedContext(context.Background(), calls, client, blockNumber, opts)
+}
+
+// AggregateStaticChunkedContext is like AggregateStaticChunked but runs with the given context.
+func (m *MultiCall) AggregateStaticChunkedContext(
+	ctx context.Context, calls []Call, client Backend, blockNumber *big.Int, opts ChunkOptions,
+) Result {
+	bounds, err := chunkBounds(Calls(calls), opts)
+	if err != nil {
+		return Result{Success: false, Error: err}
+	}
+
+	return m.runChunks(ctx, bounds, client, blockNumber, opts.Concurrency,
+		func(ctx context.Context, mc *MultiCall, start, end int, blockNumber *big.Int) Result {
+			return mc.AggregateStaticContext(ctx, calls[start:end], client, blockNumber)
+		},
+	)
+}
+
+func (m *MultiCall) TryAggregateStaticChunked(
+	calls []Call, requireSuccess bool, client Backend, blockNumber *big.Int, opts ChunkOptions,
+) Result {
+	return m.TryAggregateStaticChunkedContext(context.Background(), calls, requireSuccess, client, blockNumber, opts)
+}
+
+// TryAggregateStaticChunkedContext is like TryAggregateStaticChunked but runs with the given context.
+func (m *MultiCall) TryAggregateStaticChunkedContext(
+	ctx context.Context, calls []Call, requireSuccess bool, client Backend, blockNumber *big.Int, opts ChunkOptions,
+) Result {
+	bounds, err := chunkBounds(Calls(calls), opts)
+	if err != nil {
+		return Result{Success: false, Error: err}
+	}
+
+	return m.runChunks(ctx, bounds, client, blockNumber, opts.Concurrency,
+		func(ctx context.Context, mc *MultiCall, start, end int, blockNumber *big.Int) Result {
+			return mc.TryAggregateStaticContext(ctx, calls[start:end], requireSuccess, client, blockNumber)
+		},
+	)
+}
+
+func (m *MultiCall) TryAggregateStatic3Chunked(
+	calls []CallWithFailure, client Backend, blockNumber *big.Int, opts ChunkOptions,
+) Result {
+	return m.TryAggregateStatic3ChunkedContext(context.Background(), calls, client, blockNumber, opts)
+}
+
+// TryAggregateStatic3ChunkedContext is like TryAggregateStatic3Chunked but runs with the given context.
+func (m *MultiCall) TryAggregateStatic3ChunkedContext(
+	ctx context.Context, calls []CallWithFailure, client Backend, blockNumber *big.Int, opts ChunkOptions,
+) Result {
+	bounds, err := chunkBounds(CallsWithFailure(calls), opts)
+	if err != nil {
+		return Result{Success: false, Error: err}
+	}
+
+	return m.runChunks(ctx, bounds, client, blockNumber, opts.Concurrency,
+		func(ctx context.Context, mc *MultiCall, start, end int, blockNumber *big.Int) Result {
+			return mc.TryAggregateStatic3Context(ctx, calls[start:end], client, blockNumber)
+		},
+	)
+}
+
+type chunkFunc func(ctx context.Context, mc *MultiCall, start, end int, blockNumber *big.Int) Result
+
+// runChunks executes every chunk at the same block and concatenates the per-call results
+// in the original order, with the index of each failed call relative to the whole batch.
+// The first failing chunk cancels the remaining ones.
+func (m *MultiCall) runChunks(
+	ctx context.Context, bounds [][2]int, client Backend, blockNumber *big.Int, concurrency int, exec chunkFunc,
+) Result {
+	if blockNumber == nil {
+		blockNumberUint64, err := client.BlockNumber(ctx)
+		if err != nil {
+			return Result{Success: false, Error: fmt.Errorf("error getting block number: %w", err)}
+		}
+		blockNumber = new(big.Int).SetUint64(blockNumberUint64)
+	}
+
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]Result, len(bounds))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	failed := -1
+	for i, bound := range bounds {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i, start, end int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			// reads may downgrade the multicall type, so each chunk works on its own copy
+			mc := *m
+			results[i] = exec(ctx, &mc, start, end, new(big.Int).Set(blockNumber))
+			if !results[i].Success {
+				mu.Lock()
+				if failed == -1 {
+					failed = i
+				}
+				mu.Unlock()
+				cancel()
+			}
+		}(i, bound[0], bound[1])
+	}
+	wg.Wait()
+
+	if failed != -1 {
+		err := results[failed].Error
+		if err == nil {
+			err = fmt.Errorf("call failed")
+		}
+
+		return Result{
+			Success:  false,
+			Error:    fmt.Errorf("error in chunk %d (calls %d to %d): %w", failed, bounds[failed][0], bounds[failed][1]-1, err),
+			TxOrCall: results[failed].TxOrCall,
+		}
+	}
+
+	var result []any
+	for i, chunkResult := range results {
+		chunkValues, ok := chunkResult.Result.([]any)
+		if !ok {
+			return Result{
+				Success:  false,
+				Error:    fmt.Errorf("unexpected result type %T in chunk %d", chunkResult.Result, i),
+				TxOrCall: chunkResult.TxOrCall,
+			}
+		}
+		offsetCallErrors(chunkValues, bounds[i][0])
+		result = append(result, chunkValues...)
+	}
+
+	return Result{Success: true, Result: result, TxOrCall: TxOrCall{BlockNumber: blockNumber}}
+}
+
+// offsetCallErrors shifts the index of the call errors of a chunk's (success, returnData)
+// tuples, decoded relative to the chunk, by the index of its first call.
+func offsetCallErrors(values []any, offset int) {
+	for _, value := range values {
+		tuple, ok := value.([]any)
+		if !ok || len(tuple) != 2 {
+			continue
+		}
+		if callError, ok := tuple[1].(*CallError); ok {
+			callError.Index += offset
+		}
+	}
+}
+
+// chunkBounds returns the [start, end) index ranges of each chunk.
+func chunkBounds(calls CallsInterface, opts ChunkOptions) ([][2]int, error) {
+	callGas := opts.CallGas
+	if callGas == 0 {
+		callGas = DEFAULT_CALL_GAS
+	}
+
+	var bounds [][2]int
+	start := 0
+	var callDataBytes int
+	var gas uint64
+	for i := 0; i < calls.Len(); i++ {
+		callData, err := encodeCallData(calls, i)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding call %d: %w", i, err)
+		}
+		callGas_i := callGas + callDataGas(callData)
+
+		count := i - start
+		exceeds := (opts.MaxCalls > 0 && count+1 > opts.MaxCalls) ||
+			(opts.MaxCallDataBytes > 0 && callDataBytes+len(callData) > opts.MaxCallDataBytes) ||
+			(opts.MaxGas > 0 && gas+callGas_i > opts.MaxGas)
+		if exceeds && count > 0 {
+			bounds = append(bounds, [2]int{start, i})
+			start = i
+			callDataBytes = 0
+			gas = 0
+		}
+
+		callDataBytes += len(callData)
+		gas += callGas_i
+	}
+
+	if start < calls.Len() {
+		bounds = append(bounds, [2]int{start, calls.Len()})
+	}
+
+	return bounds, nil
+}
+
+// callDataGas returns the intrinsic gas paid for the given call data.
+func callDataGas(callData []byte) uint64 {
+	var gas uint64
+	for _, b := range callData {
+		if b == 0 {
+			gas += 4
+		} else {
+			gas += 16
+		}
+	}
+
+	return gas
+}
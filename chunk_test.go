@@ -0,0 +1,146 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func newRawCalls(callDatas ...[]byte) Calls {
+	targets := make([]common.Address, len(callDatas))
+	funcSignatures := make([]string, len(callDatas))
+
+	return NewCalls(targets, funcSignatures, nil, callDatas, nil, nil)
+}
+
+func TestChunkBounds(t *testing.T) {
+	calls := newRawCalls(
+		make([]byte, 10), make([]byte, 10), make([]byte, 10), make([]byte, 30), make([]byte, 10),
+	)
+
+	tests := []struct {
+		name     string
+		opts     ChunkOptions
+		expected [][2]int
+	}{
+		{"no limits", ChunkOptions{}, [][2]int{{0, 5}}},
+		{"max calls", ChunkOptions{MaxCalls: 2}, [][2]int{{0, 2}, {2, 4}, {4, 5}}},
+		{"max call data", ChunkOptions{MaxCallDataBytes: 25}, [][2]int{{0, 2}, {2, 3}, {3, 4}, {4, 5}}},
+		{"max gas", ChunkOptions{MaxGas: 110, CallGas: 10}, [][2]int{{0, 2}, {2, 3}, {3, 4}, {4, 5}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bounds, err := chunkBounds(calls, test.opts)
+			if err != nil {
+				t.Fatalf("error computing bounds: %v", err)
+			}
+			if !reflect.DeepEqual(bounds, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, bounds)
+			}
+		})
+	}
+}
+
+func TestRunChunksKeepsOrderAndPinsBlock(t *testing.T) {
+	backend := newEVMBackend(t)
+	mcall := &MultiCall{MultiCallType: DEPLOYLESS}
+	bounds := [][2]int{{0, 2}, {2, 3}, {3, 6}}
+
+	result := mcall.runChunks(context.Background(), bounds, backend, nil, 3,
+		func(ctx context.Context, mc *MultiCall, start, end int, blockNumber *big.Int) Result {
+			if blockNumber.Uint64() != backend.blockNumber {
+				return Result{Success: false, Error: fmt.Errorf("unexpected block %s", blockNumber)}
+			}
+
+			var values []any
+			for i := start; i < end; i++ {
+				values = append(values, i)
+			}
+
+			return Result{Success: true, Result: values}
+		},
+	)
+	if !result.Success {
+		t.Fatalf("chunks failed: %v", result.Error)
+	}
+
+	expected := []any{0, 1, 2, 3, 4, 5}
+	if !reflect.DeepEqual(result.Result, expected) {
+		t.Errorf("expected %v, got %v", expected, result.Result)
+	}
+	if result.TxOrCall.BlockNumber.Uint64() != backend.blockNumber {
+		t.Errorf("expected block %d, got %s", backend.blockNumber, result.TxOrCall.BlockNumber)
+	}
+}
+
+func TestRunChunksReportsFailingChunk(t *testing.T) {
+	backend := newEVMBackend(t)
+	mcall := &MultiCall{MultiCallType: DEPLOYLESS}
+	bounds := [][2]int{{0, 2}, {2, 4}}
+
+	result := mcall.runChunks(context.Background(), bounds, backend, big.NewInt(1), 1,
+		func(ctx context.Context, mc *MultiCall, start, end int, blockNumber *big.Int) Result {
+			if start == 2 {
+				return Result{Success: false, Error: fmt.Errorf("boom")}
+			}
+
+			return Result{Success: true, Result: []any{start}}
+		},
+	)
+	if result.Success {
+		t.Fatalf("expected failure")
+	}
+	if result.Error.Error() != "error in chunk 1 (calls 2 to 3): boom" {
+		t.Errorf("unexpected error: %v", result.Error)
+	}
+}
+
+func TestTryAggregateStaticChunkedIndexesCallErrors(t *testing.T) {
+	backend := newEVMBackend(t)
+	echo, reverting := common.HexToAddress("0x4444"), common.HexToAddress("0x5555")
+	backend.setCode(echo, echoCode)
+	backend.setCode(reverting, revertCode)
+	mcall := &MultiCall{MultiCallType: DEPLOYLESS}
+
+	calls := Calls{
+		NewCall(echo, "", nil, []byte{0x01}, nil, nil),
+		NewCall(echo, "", nil, []byte{0x02}, nil, nil),
+		NewCall(echo, "", nil, []byte{0x03}, nil, nil),
+		NewCall(reverting, "", nil, []byte{0x04}, nil, nil),
+	}
+	withFailure := make([]CallWithFailure, len(calls))
+	for i, call := range calls {
+		withFailure[i] = CallWithFailure{Call: call}
+	}
+
+	for _, test := range []struct {
+		name   string
+		result func() Result
+	}{
+		{"try aggregate", func() Result {
+			return mcall.TryAggregateStaticChunked(calls, false, backend, nil, ChunkOptions{MaxCalls: 2})
+		}},
+		{"try aggregate3", func() Result {
+			return mcall.TryAggregateStatic3Chunked(withFailure, backend, nil, ChunkOptions{MaxCalls: 2})
+		}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result := test.result()
+			if !result.Success {
+				t.Fatalf("chunks failed: %v", result.Error)
+			}
+
+			values := result.Result.([]any)
+			var callError *CallError
+			if len(values) != 4 || !errors.As(values[3].([]any)[1].(error), &callError) || callError.Index != 3 {
+				t.Errorf("expected call error for call 3, got %v", values)
+			}
+		})
+	}
+}
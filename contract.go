@@ -0,0 +1,142 @@
+package multicall
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	ethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Contract binds a contract ABI to an address to create calls by method name, with the
+// return types inferred from the method outputs.
+type Contract struct {
+	Address common.Address
+	ABI     ethabi.ABI
+}
+
+func NewContract(address common.Address, contractABI ethabi.ABI) *Contract {
+	return &Contract{Address: address, ABI: contractABI}
+}
+
+// NewContractFromJSON parses the given ABI JSON, e.g. GENERAL_MULTICALL_ABI.
+func NewContractFromJSON(address common.Address, abiJSON string) (*Contract, error) {
+	contractABI, err := ethabi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing ABI: %w", err)
+	}
+
+	return NewContract(address, contractABI), nil
+}
+
+// NewCall creates a call of the given method. The arguments are packed by go-ethereum,
+// so they take its Go types (e.g. common.Address, *big.Int, structs for tuples).
+func (c *Contract) NewCall(method string, args ...any) (Call, error) {
+	abiMethod, callData, err := c.pack(method, args)
+	if err != nil {
+		return Call{}, err
+	}
+
+	return NewCall(c.Address, abiMethod.Sig, args, callData, outputTypes(abiMethod), nil), nil
+}
+
+func (c *Contract) NewCallWithFailure(method string, requireSuccess bool, args ...any) (CallWithFailure, error) {
+	call, err := c.NewCall(method, args...)
+	if err != nil {
+		return CallWithFailure{}, err
+	}
+
+	return CallWithFailure{Call: call, RequireSuccess: requireSuccess}, nil
+}
+
+// Unpack decodes the return data of the given method into out, a pointer to a struct
+// whose fields match the output names or to the single output value.
+func (c *Contract) Unpack(out any, method string, returnData []byte) error {
+	return c.ABI.UnpackIntoInterface(out, method, returnData)
+}
+
+// UnpackMap decodes the return data of the given method into a map keyed by output name.
+// Unnamed outputs are keyed by their index.
+func (c *Contract) UnpackMap(method string, returnData []byte) (map[string]any, error) {
+	abiMethod, ok := c.ABI.Methods[method]
+	if !ok {
+		return nil, fmt.Errorf("method %s not found in ABI", method)
+	}
+
+	values, err := abiMethod.Outputs.Unpack(returnData)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]any, len(values))
+	for i, output := range abiMethod.Outputs {
+		name := output.Name
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		result[name] = values[i]
+	}
+
+	return result, nil
+}
+
+// BindCall creates a TypedCall of the given method decoding its outputs into a T, either
+// a struct whose fields match the output names or the type of the single output.
+func BindCall[T any](c *Contract, method string, args ...any) (TypedCall[T], error) {
+	call, err := c.NewCall(method, args...)
+	if err != nil {
+		return TypedCall[T]{}, err
+	}
+
+	return TypedCall[T]{
+		Call: call,
+		Decoder: func(returnData []byte) (T, error) {
+			var value T
+			err := c.Unpack(&value, method, returnData)
+
+			return value, err
+		},
+	}, nil
+}
+
+// BindMapCall creates a TypedCall of the given method decoding its outputs into a map
+// keyed by output name.
+func BindMapCall(c *Contract, method string, args ...any) (TypedCall[map[string]any], error) {
+	call, err := c.NewCall(method, args...)
+	if err != nil {
+		return TypedCall[map[string]any]{}, err
+	}
+
+	return TypedCall[map[string]any]{
+		Call: call,
+		Decoder: func(returnData []byte) (map[string]any, error) {
+			return c.UnpackMap(method, returnData)
+		},
+	}, nil
+}
+
+func (c *Contract) pack(method string, args []any) (ethabi.Method, []byte, error) {
+	abiMethod, ok := c.ABI.Methods[method]
+	if !ok {
+		return ethabi.Method{}, nil, fmt.Errorf("method %s not found in ABI", method)
+	}
+
+	callData, err := c.ABI.Pack(method, args...)
+	if err != nil {
+		return ethabi.Method{}, nil, fmt.Errorf("error packing %s: %w", abiMethod.Sig, err)
+	}
+
+	return abiMethod, callData, nil
+}
+
+// outputTypes returns the canonical types of the method outputs, e.g. "(uint256,address)"
+// for a struct output.
+func outputTypes(method ethabi.Method) []string {
+	types := make([]string, len(method.Outputs))
+	for i, output := range method.Outputs {
+		types[i] = output.Type.String()
+	}
+
+	return types
+}
@@ -0,0 +1,98 @@
+package multicall
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/omnes-tech/abi"
+)
+
+const testContractABI = `[
+	{"type":"function","name":"balanceOf","stateMutability":"view",
+	 "inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"position","stateMutability":"view",
+	 "inputs":[{"name":"id","type":"uint256"}],
+	 "outputs":[{"name":"owner","type":"address"},{"name":"amount","type":"uint256"},{"name":"open","type":"bool"}]}
+]`
+
+func TestContractCalls(t *testing.T) {
+	contract, err := NewContractFromJSON(common.HexToAddress("0x4444"), testContractABI)
+	if err != nil {
+		t.Fatalf("error parsing ABI: %v", err)
+	}
+	owner := common.HexToAddress("0x1111")
+
+	call, err := contract.NewCall("balanceOf", owner)
+	if err != nil {
+		t.Fatalf("error creating call: %v", err)
+	}
+	expectedCallData, err := abi.EncodeWithSignature("balanceOf(address)", &owner)
+	if err != nil {
+		t.Fatalf("error encoding call: %v", err)
+	}
+	if call.FuncSignature != "balanceOf(address)" || !reflect.DeepEqual(call.CallData, expectedCallData) {
+		t.Errorf("unexpected call %s with data %x", call.FuncSignature, call.CallData)
+	}
+	if !reflect.DeepEqual(call.ReturnTypes, []string{"uint256"}) {
+		t.Errorf("unexpected return types %v", call.ReturnTypes)
+	}
+
+	if _, err := contract.NewCall("transfer", owner); err == nil {
+		t.Errorf("expected error for unknown method")
+	}
+	if _, err := contract.NewCall("balanceOf", big.NewInt(1)); err == nil {
+		t.Errorf("expected error for invalid argument")
+	}
+}
+
+func TestContractDecoding(t *testing.T) {
+	contract, err := NewContractFromJSON(common.HexToAddress("0x4444"), testContractABI)
+	if err != nil {
+		t.Fatalf("error parsing ABI: %v", err)
+	}
+	owner := common.HexToAddress("0x1111")
+	returnData, err := abi.Encode([]string{"address", "uint256", "bool"}, &owner, big.NewInt(12), true)
+	if err != nil {
+		t.Fatalf("error encoding return data: %v", err)
+	}
+
+	type position struct {
+		Owner  common.Address
+		Amount *big.Int
+		Open   bool
+	}
+	positionCall, err := BindCall[position](contract, "position", big.NewInt(1))
+	if err != nil {
+		t.Fatalf("error binding call: %v", err)
+	}
+	value, err := positionCall.Decoder(returnData)
+	if err != nil {
+		t.Fatalf("error decoding struct: %v", err)
+	}
+	if value.Owner != owner || value.Amount.Int64() != 12 || !value.Open {
+		t.Errorf("unexpected position %+v", value)
+	}
+
+	mapCall, err := BindMapCall(contract, "position", big.NewInt(1))
+	if err != nil {
+		t.Fatalf("error binding call: %v", err)
+	}
+	values, err := mapCall.Decoder(returnData)
+	if err != nil {
+		t.Fatalf("error decoding map: %v", err)
+	}
+	if values["owner"] != owner || values["amount"].(*big.Int).Int64() != 12 || values["open"] != true {
+		t.Errorf("unexpected values %v", values)
+	}
+
+	balanceCall, err := BindCall[*big.Int](contract, "balanceOf", owner)
+	if err != nil {
+		t.Fatalf("error binding call: %v", err)
+	}
+	balance, err := balanceCall.Decoder(encodeUint256(t, 5))
+	if err != nil || balance.Int64() != 5 {
+		t.Errorf("expected balance 5, got %v (%v)", balance, err)
+	}
+}
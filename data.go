@@ -20,3 +20,31 @@ const DEPLOYLESS_MULTICALL_BYTECODE = "0x608060405260405161229938038061229983398
 var ZERO_ADDRESS = common.Address{}
 
 const MINING_WAIT_DURATION = 600 * time.Second
+
+// DEFAULT_RECEIPT_POLL_INTERVAL is how often pending replacement transactions are checked.
+const DEFAULT_RECEIPT_POLL_INTERVAL = time.Second
+
+// MIN_LEGACY_PRICE_BUMP and MIN_DYNAMIC_FEE_PRICE_BUMP are the minimum fee increases, in
+// percent, nodes require to accept a replacement transaction.
+const MIN_LEGACY_PRICE_BUMP = 10.0
+const MIN_DYNAMIC_FEE_PRICE_BUMP = 12.5
+
+// MAX_NONCE_RESYNCS is how many times a transaction rejected with a nonce too low is
+// re-signed with a resynced nonce of the NonceManager.
+const MAX_NONCE_RESYNCS = 3
+
+// DEFAULT_CALL_GAS is the execution gas assumed for each call when chunking by gas.
+const DEFAULT_CALL_GAS = 50_000
+
+// ERC-165 interface ids.
+var (
+	ERC165_INTERFACE_ID           = [4]byte{0x01, 0xff, 0xc9, 0xa7}
+	INVALID_INTERFACE_ID          = [4]byte{0xff, 0xff, 0xff, 0xff}
+	ERC721_INTERFACE_ID           = [4]byte{0x80, 0xac, 0x58, 0xcd}
+	ERC721_METADATA_INTERFACE_ID  = [4]byte{0x5b, 0x5e, 0x13, 0x9f}
+	ERC1155_INTERFACE_ID          = [4]byte{0xd9, 0xb6, 0x7a, 0x26}
+	ERC1155_METADATA_INTERFACE_ID = [4]byte{0x0e, 0x89, 0x34, 0x1c}
+)
+
+// ERC1271_MAGIC_VALUE is returned by isValidSignature(bytes32,bytes) for valid signatures.
+var ERC1271_MAGIC_VALUE = [4]byte{0x16, 0x26, 0xba, 0x7e}
@@ -0,0 +1,240 @@
+package multicall
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/omnes-tech/abi"
+)
+
+// memory layout of the delegate call simulator: the revert data is built from
+// simulationOutput, and the end of the encoded data is kept at freePointer.
+const (
+	freePointer      = 0x40
+	simulationOutput = 0x80
+	simulationArray  = simulationOutput + 4
+	simulationHeads  = simulationArray + 0x40
+)
+
+// deploylessDelegateSimulation runs the calls with DELEGATECALL from a generated simulator
+// and decodes the MultiCall__Simulation revert. Without an account the calls run in a
+// fresh deployless contract, otherwise in the context (storage, balance, address) of the
+// account, whose code is replaced by the simulator with a state override.
+func deploylessDelegateSimulation(
+	ctx context.Context, calls Calls, account *common.Address, client Backend, blockNumber *big.Int,
+	overrides *CallOverrides,
+) Result {
+	code, err := delegateSimulatorCode(calls)
+	if err != nil {
+		return Result{Success: false, Error: err}
+	}
+
+	callArgs := map[string]interface{}{"to": nil, "data": hexutil.Encode(code)}
+	if account != nil {
+		callArgs = map[string]interface{}{"to": account, "data": "0x"}
+		overrides = withCode(overrides, *account, code)
+	}
+
+	txOrCall := TxOrCall{To: account, Data: code, BlockNumber: blockNumber}
+	_, err = ethCall(ctx, client, callArgs, blockNumber, overrides)
+	if err == nil {
+		return Result{Success: false, Error: fmt.Errorf("call did not returned simulation result"), TxOrCall: txOrCall}
+	}
+	if !strings.Contains(err.Error(), "execution reverted") {
+		return Result{Success: false, Error: fmt.Errorf("error making delegate call simulation: %w", err), TxOrCall: txOrCall}
+	}
+	encodedRevert, ok := parseRevertData(err)
+	if !ok {
+		return Result{Success: false, Error: err, TxOrCall: txOrCall}
+	}
+
+	decodedRevert, err := safeDecodeWithSignature(SIMULATION_ERROR_SIGNATURE, encodedRevert)
+	if err != nil {
+		return Result{Success: false, Error: err, TxOrCall: txOrCall}
+	}
+	simulation, err := newSimulation(calls, decodedRevert[0].([]any))
+	if err != nil {
+		return Result{Success: false, Error: err, TxOrCall: txOrCall}
+	}
+
+	if txOrCall.BlockNumber == nil {
+		blockNumberUint64, err := client.BlockNumber(ctx)
+		if err != nil {
+			return Result{Success: false, Error: err, TxOrCall: txOrCall}
+		}
+		txOrCall.BlockNumber = new(big.Int).SetUint64(blockNumberUint64)
+	}
+
+	return Result{Success: true, Result: simulation, TxOrCall: txOrCall}
+}
+
+// withCode returns a copy of the overrides replacing the code of the given account.
+func withCode(overrides *CallOverrides, account common.Address, code []byte) *CallOverrides {
+	withCode := &CallOverrides{State: StateOverride{}}
+	if overrides != nil {
+		withCode.Block = overrides.Block
+		for address, override := range overrides.State {
+			withCode.State[address] = override
+		}
+	}
+
+	override := withCode.State[account]
+	override.Code = code
+	withCode.State[account] = override
+
+	return withCode
+}
+
+// delegateSimulatorCode assembles bytecode delegate calling every call in order and
+// reverting with MultiCall__Simulation, holding the success, return data and gas used of
+// each call. The call data is appended to the program and copied with CODECOPY, so the
+// same bytecode runs as creation code or as the code of an overridden account.
+func delegateSimulatorCode(calls Calls) ([]byte, error) {
+	callDatas := make([][]byte, len(calls))
+	for i := range calls {
+		callData, err := encodeCallData(calls, i)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding call %d: %w", i, err)
+		}
+		callDatas[i] = callData
+	}
+
+	program := assembleDelegateSimulator(calls, callDatas, 0)
+	program = assembleDelegateSimulator(calls, callDatas, uint64(len(program)))
+
+	code := program
+	for _, callData := range callDatas {
+		code = append(code, callData...)
+	}
+
+	return code, nil
+}
+
+func assembleDelegateSimulator(calls Calls, callDatas [][]byte, dataOffset uint64) []byte {
+	var a assembler
+
+	selector := make([]byte, 32)
+	copy(selector, abi.EncodeSignature(SIMULATION_ERROR_SIGNATURE))
+	a.pushBytes(selector)
+	a.push(simulationOutput)
+	a.op(vm.MSTORE)
+	a.push(0x20)
+	a.push(simulationArray)
+	a.op(vm.MSTORE)
+	a.push(uint64(len(calls)))
+	a.push(simulationArray + 0x20)
+	a.op(vm.MSTORE)
+	a.push(simulationHeads + 0x20*uint64(len(calls)))
+	a.push(freePointer)
+	a.op(vm.MSTORE)
+
+	for i, call := range calls {
+		// head: offset of the (success, returnData, gasUsed) tuple
+		a.push(simulationHeads)
+		a.loadFreePointer(0)
+		a.op(vm.SUB)
+		a.push(simulationHeads + 0x20*uint64(i))
+		a.op(vm.MSTORE)
+
+		// call data copied where the return data is written afterwards
+		a.push(uint64(len(callDatas[i])))
+		a.pushFixed(dataOffset, 4)
+		a.loadFreePointer(0x80)
+		a.op(vm.CODECOPY)
+		dataOffset += uint64(len(callDatas[i]))
+
+		a.op(vm.GAS)
+		a.push(0)
+		a.push(0)
+		a.push(uint64(len(callDatas[i])))
+		a.loadFreePointer(0x80)
+		a.pushBytes(call.Target.Bytes())
+		a.op(vm.GAS, vm.DELEGATECALL)
+		a.loadFreePointer(0)
+		a.op(vm.MSTORE)
+
+		a.op(vm.GAS, vm.SWAP1, vm.SUB)
+		a.loadFreePointer(0x40)
+		a.op(vm.MSTORE)
+		a.push(0x60)
+		a.loadFreePointer(0x20)
+		a.op(vm.MSTORE)
+		a.op(vm.RETURNDATASIZE)
+		a.loadFreePointer(0x60)
+		a.op(vm.MSTORE)
+
+		// zeroes the padding, then copies the return data
+		a.push(0)
+		a.op(vm.RETURNDATASIZE)
+		a.loadFreePointer(0x80)
+		a.op(vm.ADD, vm.MSTORE)
+		a.op(vm.RETURNDATASIZE)
+		a.push(0)
+		a.loadFreePointer(0x80)
+		a.op(vm.RETURNDATACOPY)
+
+		a.push(0x1f)
+		a.op(vm.RETURNDATASIZE, vm.ADD)
+		a.pushBytes(common.FromHex("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffe0"))
+		a.op(vm.AND)
+		a.loadFreePointer(0x80)
+		a.op(vm.ADD)
+		a.push(freePointer)
+		a.op(vm.MSTORE)
+	}
+
+	a.push(simulationOutput)
+	a.loadFreePointer(0)
+	a.op(vm.SUB)
+	a.push(simulationOutput)
+	a.op(vm.REVERT)
+
+	return a.code
+}
+
+// assembler builds EVM bytecode.
+type assembler struct {
+	code []byte
+}
+
+func (a *assembler) op(ops ...vm.OpCode) {
+	for _, op := range ops {
+		a.code = append(a.code, byte(op))
+	}
+}
+
+// push pushes value with the smallest PUSH instruction. PUSH0 is avoided to support
+// chains without Shanghai.
+func (a *assembler) push(value uint64) {
+	if value == 0 {
+		a.pushBytes([]byte{0})
+		return
+	}
+
+	a.pushBytes(new(big.Int).SetUint64(value).Bytes())
+}
+
+// pushFixed pushes value on exactly size bytes, so the program length does not depend on it.
+func (a *assembler) pushFixed(value uint64, size int) {
+	a.pushBytes(common.LeftPadBytes(new(big.Int).SetUint64(value).Bytes(), size))
+}
+
+func (a *assembler) pushBytes(value []byte) {
+	a.op(vm.PUSH1 + vm.OpCode(len(value)-1))
+	a.code = append(a.code, value...)
+}
+
+// loadFreePointer pushes the free pointer plus offset.
+func (a *assembler) loadFreePointer(offset uint64) {
+	a.push(freePointer)
+	a.op(vm.MLOAD)
+	if offset > 0 {
+		a.push(offset)
+		a.op(vm.ADD)
+	}
+}
@@ -0,0 +1,98 @@
+package multicall
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// addressCode returns the address it runs at: ADDRESS PUSH0 MSTORE PUSH1 0x20 PUSH0 RETURN.
+var addressCode = common.FromHex("0x305f5260205ff3")
+
+// revertCode reverts without data: PUSH0 PUSH0 REVERT.
+var revertCode = common.FromHex("0x5f5ffd")
+
+func TestSimulateDelegateCalls(t *testing.T) {
+	backend := newEVMBackend(t)
+	addressTarget := common.HexToAddress("0x4444")
+	revertTarget := common.HexToAddress("0x5555")
+	echoTarget := common.HexToAddress("0x6666")
+	backend.setCode(addressTarget, addressCode)
+	backend.setCode(revertTarget, revertCode)
+	backend.setCode(echoTarget, echoCode)
+	account := common.HexToAddress("0x7777")
+
+	mcall := &MultiCall{MultiCallType: DEPLOYLESS}
+	calls := NewCalls(
+		[]common.Address{addressTarget, revertTarget, echoTarget},
+		[]string{"", "", ""},
+		nil,
+		[][]byte{{0x01}, {0x02}, common.FromHex("0x" + common.Bytes2Hex(make([]byte, 40)) + "ff")},
+		nil,
+		nil,
+	)
+
+	for _, test := range []struct {
+		name    string
+		account *common.Address
+	}{
+		{"deployless context", nil},
+		{"account context", &account},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result := mcall.SimulateDelegateCalls(calls, test.account, backend, nil)
+			if !result.Success {
+				t.Fatalf("simulation failed: %v", result.Error)
+			}
+
+			simulation := result.Result.(Simulation)
+			if len(simulation.Results) != 3 {
+				t.Fatalf("expected 3 results, got %d", len(simulation.Results))
+			}
+
+			contextAddress := common.BytesToAddress(simulation.Results[0].ReturnData)
+			if test.account != nil && contextAddress != account {
+				t.Errorf("expected calls to run at %s, got %s", account, contextAddress)
+			}
+			if contextAddress == addressTarget || contextAddress == (common.Address{}) {
+				t.Errorf("expected delegate call context, got %s", contextAddress)
+			}
+
+			if simulation.Results[1].Success || !simulation.Results[2].Success || simulation.Failed != 1 {
+				t.Errorf("unexpected success flags %+v", simulation)
+			}
+			if !bytes.Equal(simulation.Results[2].ReturnData, calls[2].CallData) {
+				t.Errorf("expected echoed call data, got %x", simulation.Results[2].ReturnData)
+			}
+			if simulation.Results[0].GasUsed.Sign() <= 0 {
+				t.Errorf("expected gas used, got %s", simulation.Results[0].GasUsed)
+			}
+		})
+	}
+}
+
+func TestSimulateDelegateCallsRejections(t *testing.T) {
+	backend := newEVMBackend(t)
+	target := common.HexToAddress("0x4444")
+	backend.setCode(target, echoCode)
+	calls := NewCalls([]common.Address{target}, []string{""}, nil, [][]byte{{0x01}}, nil, nil)
+
+	for _, multiCallType := range []MultiCallType{GENERAL, OMNES} {
+		mcall := &MultiCall{MultiCallType: multiCallType}
+		if result := mcall.SimulateDelegateCalls(calls, nil, backend, nil); result.Success {
+			t.Errorf("expected multi call type %d to be rejected", multiCallType)
+		}
+	}
+
+	mcall := &MultiCall{MultiCallType: DEPLOYLESS}
+	calls[0].Value = big.NewInt(1)
+	if result := mcall.SimulateDelegateCalls(calls, nil, backend, nil); result.Success {
+		t.Errorf("expected call with value to be rejected")
+	}
+	calls[0].Value = big.NewInt(0)
+	if result := mcall.SimulateDelegateCalls(calls, nil, backend, nil); !result.Success {
+		t.Errorf("expected call with zero value to run: %v", result.Error)
+	}
+}
@@ -3,22 +3,21 @@ package multicall
 import (
 	"context"
 	"fmt"
-	"log"
 	"math/big"
-	"strings"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/omnes-tech/abi"
 )
 
 func transactWithFailure(
-	calls CallsWithFailure, requireSuccess bool, client *ethclient.Client,
+	ctx context.Context, calls CallsWithFailure, requireSuccess bool, client Backend,
 	signer SignerInterface, to *common.Address, funcSignature string, txReturnTypes []string,
-	withValue bool, isMultiCall3Type bool,
+	withValue bool, isMultiCall3Type bool, opts *TxOptions,
 ) Result {
 	return write(
+		ctx,
 		calls,
 		requireSuccess,
 		client,
@@ -28,15 +27,17 @@ func transactWithFailure(
 		txReturnTypes,
 		withValue,
 		isMultiCall3Type,
+		opts,
 	)
 }
 
 func transact(
-	calls Calls, requireSuccess bool, client *ethclient.Client,
+	ctx context.Context, calls Calls, requireSuccess bool, client Backend,
 	signer SignerInterface, to *common.Address, funcSignature string, txReturnTypes []string,
-	withValue bool, isMultiCall3Type bool,
+	withValue bool, isMultiCall3Type bool, opts *TxOptions,
 ) Result {
 	return write(
+		ctx,
 		calls,
 		requireSuccess,
 		client,
@@ -46,50 +47,48 @@ func transact(
 		txReturnTypes,
 		withValue,
 		isMultiCall3Type,
+		opts,
 	)
 }
 
 func write(
-	calls CallsInterface, requireSuccess bool, client *ethclient.Client, signer SignerInterface,
+	ctx context.Context, calls CallsInterface, requireSuccess bool, client Backend, signer SignerInterface,
 	to *common.Address, funcSignature string, txReturnTypes []string, withValue bool, isMultiCall3Type bool,
+	opts *TxOptions,
 ) Result {
-	arrayfiedCalls, msgValue, err := calls.ToArray(withValue, isMultiCall3Type)
-	if err != nil {
-		return Result{Success: false, Error: err}
+	if opts != nil && opts.Replacement != nil && opts.Replacement.Timeout <= 0 && opts.Replacement.Blocks == 0 {
+		return Result{Success: false, Error: fmt.Errorf("replacement policy without a timeout or a number of blocks")}
 	}
 
-	var callData []byte
-	if funcSignature == "tryAggregateCalls((address,bytes,uint256)[],bool)" {
-		callData, err = abi.EncodeWithSignature(funcSignature, arrayfiedCalls, requireSuccess)
-	} else {
-		callData, err = abi.EncodeWithSignature(funcSignature, arrayfiedCalls)
-	}
-	if err != nil {
-		return Result{Success: false, Error: err}
-	}
-
-	tx, err := createTransaction(client, signer.GetAddress(), to, msgValue, callData)
+	tx, chainId, err := buildTransaction(
+		ctx, calls, requireSuccess, client, signer.GetAddress(), to, funcSignature, withValue, isMultiCall3Type, opts,
+	)
 	if err != nil {
 		return Result{Success: false, Error: err, TxOrCall: FromTxToTxOrCall(tx, *signer.GetAddress(), nil)}
 	}
+	callData, msgValue := tx.Data(), tx.Value()
 
-	chainId, err := client.ChainID(context.Background())
-	if err != nil {
-		return Result{Success: false, Error: err, TxOrCall: FromTxToTxOrCall(tx, *signer.GetAddress(), nil)}
+	// the nonce allocated by the nonce manager is released unless the transaction is sent
+	var nonces *NonceManager
+	if opts != nil && opts.Nonce == nil {
+		nonces = opts.NonceManager
 	}
-
-	signedTx, err := signer.SignTx(tx, chainId)
-	if err != nil {
-		return Result{Success: false, Error: err, TxOrCall: FromTxToTxOrCall(tx, *signer.GetAddress(), nil)}
+	releaseNonce := func() {
+		if nonces != nil {
+			nonces.Release(*signer.GetAddress(), tx.Nonce())
+		}
 	}
 
-	encodedCallResult, err := client.CallContract(context.Background(), ethereum.CallMsg{
-		From: *signer.GetAddress(),
-		To:   to,
-		Data: callData,
+	// simulated before signing, so a remote signer is not asked to approve a failing transaction
+	encodedCallResult, err := client.CallContract(ctx, ethereum.CallMsg{
+		From:  *signer.GetAddress(),
+		To:    to,
+		Value: msgValue,
+		Data:  callData,
 	}, nil)
 	if err != nil {
-		blockNumber, err := client.BlockNumber(context.Background())
+		releaseNonce()
+		blockNumber, err := client.BlockNumber(ctx)
 		if err != nil {
 			return Result{Success: false, Error: err, TxOrCall: FromTxToTxOrCall(tx, *signer.GetAddress(), nil)}
 		}
@@ -101,32 +100,106 @@ func write(
 		}
 	}
 
-	receipt, err := sendSignedTransaction(client, signedTx)
+	signedTx, err := signer.SignTx(tx, chainId)
+	if err != nil {
+		releaseNonce()
+		return Result{Success: false, Error: err, TxOrCall: FromTxToTxOrCall(tx, *signer.GetAddress(), nil)}
+	}
+
+	if opts != nil && opts.NoSend {
+		releaseNonce()
+		blockNumber, err := client.BlockNumber(ctx)
+		if err != nil {
+			return Result{Success: false, Error: err, TxOrCall: FromTxToTxOrCall(signedTx, *signer.GetAddress(), nil)}
+		}
+		txOrCall := FromTxToTxOrCall(signedTx, *signer.GetAddress(), new(big.Int).SetUint64(blockNumber))
+
+		decodedCallResult, err := abi.Decode(txReturnTypes, encodedCallResult)
+		if err != nil {
+			return Result{Success: false, Error: fmt.Errorf("error decoding call result: %w", err), TxOrCall: txOrCall}
+		}
+
+		return parseResults(decodedCallResult, true, signedTx, txOrCall)
+	}
+
+	var receipt *types.Receipt
+	var replacedHashes []common.Hash
+	sentTx, err := broadcastTransaction(ctx, client, signer, chainId, signedTx, nonces)
+	if err == nil {
+		signedTx = sentTx
+		if opts != nil && opts.Replacement != nil {
+			receipt, signedTx, replacedHashes, err = waitWithReplacement(ctx, client, signer, chainId, signedTx, opts.Replacement)
+		} else {
+			receipt, err = waitMined(ctx, client, signedTx)
+		}
+	}
 	if err != nil {
 		return Result{
 			Success:  false,
 			Error:    fmt.Errorf("error sending signed transaction: %w", err),
-			TxOrCall: FromTxToTxOrCall(tx, *signer.GetAddress(), receipt.BlockNumber),
+			TxOrCall: FromTxToTxOrCall(signedTx, *signer.GetAddress(), nil),
 		}
 	}
+	txOrCall := FromTxToTxOrCall(signedTx, *signer.GetAddress(), receipt.BlockNumber)
+	txOrCall.ReplacedHashes = replacedHashes
 
 	decodedCallResult, err := abi.Decode(txReturnTypes, encodedCallResult)
 	if err != nil {
 		return Result{
 			Success:  false,
 			Error:    fmt.Errorf("error decoding call result: %w", err),
-			TxOrCall: FromTxToTxOrCall(tx, *signer.GetAddress(), receipt.BlockNumber),
+			TxOrCall: txOrCall,
 		}
 	}
 
-	return parseResults(decodedCallResult, receipt.Status == 1, receipt, FromTxToTxOrCall(signedTx, *signer.GetAddress(), receipt.BlockNumber))
+	return parseResults(decodedCallResult, receipt.Status == 1, receipt, txOrCall)
+}
+
+// buildTransaction encodes the calls for the given multicall function and creates the
+// unsigned transaction sending them.
+func buildTransaction(
+	ctx context.Context, calls CallsInterface, requireSuccess bool, client Backend, from *common.Address,
+	to *common.Address, funcSignature string, withValue bool, isMultiCall3Type bool, opts *TxOptions,
+) (*types.Transaction, *big.Int, error) {
+	arrayfiedCalls, msgValue, err := calls.ToArray(withValue, isMultiCall3Type)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var callData []byte
+	if funcSignature == "tryAggregateCalls((address,bytes,uint256)[],bool)" {
+		callData, err = abi.EncodeWithSignature(funcSignature, arrayfiedCalls, requireSuccess)
+	} else {
+		callData, err = abi.EncodeWithSignature(funcSignature, arrayfiedCalls)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if opts != nil && opts.Value != nil {
+		msgValue = opts.Value
+	}
+
+	chainId, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := createTransaction(ctx, client, chainId, from, to, msgValue, callData, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tx, chainId, nil
 }
 
 func txAsReadWithFailure(
-	calls CallsWithFailure, requireSuccess bool, client *ethclient.Client, to *common.Address,
+	ctx context.Context, calls CallsWithFailure, requireSuccess bool, client Backend, to *common.Address,
 	funcSignature string, txReturnTypes []string, multiCallType *MultiCallType, blockNumber *big.Int,
+	overrides *CallOverrides,
 ) Result {
 	return asRead(
+		ctx,
 		calls,
 		requireSuccess,
 		client,
@@ -135,14 +208,17 @@ func txAsReadWithFailure(
 		txReturnTypes,
 		multiCallType,
 		blockNumber,
+		overrides,
 	)
 }
 
 func txAsRead(
-	calls Calls, requireSuccess bool, client *ethclient.Client, to *common.Address,
+	ctx context.Context, calls Calls, requireSuccess bool, client Backend, to *common.Address,
 	funcSignature string, txReturnTypes []string, multiCallType *MultiCallType, blockNumber *big.Int,
+	overrides *CallOverrides,
 ) Result {
 	return asRead(
+		ctx,
 		calls,
 		requireSuccess,
 		client,
@@ -151,12 +227,14 @@ func txAsRead(
 		txReturnTypes,
 		multiCallType,
 		blockNumber,
+		overrides,
 	)
 }
 
 func asRead(
-	calls CallsInterface, requireSuccess bool, client *ethclient.Client, to *common.Address,
+	ctx context.Context, calls CallsInterface, requireSuccess bool, client Backend, to *common.Address,
 	funcSignature string, txReturnTypes []string, multiCallType *MultiCallType, blockNumber *big.Int,
+	overrides *CallOverrides,
 ) Result {
 	arrayfiedCalls, _, err := calls.ToArray(true, false)
 	if err != nil {
@@ -174,6 +252,7 @@ func asRead(
 	}
 
 	decodedCallResult, decodedAggregatedCallsResultVar, call, err := makeCall(
+		ctx,
 		calls,
 		client,
 		to,
@@ -181,8 +260,8 @@ func asRead(
 		txReturnTypes,
 		false,
 		multiCallType,
-		nil,
 		blockNumber,
+		overrides,
 	)
 	if err != nil {
 		return Result{Success: false, Error: err, TxOrCall: call}
@@ -192,11 +271,12 @@ func asRead(
 }
 
 func call(
-	calls Calls, requireSuccess bool, client *ethclient.Client, to *common.Address, funcSignature string,
-	txReturnTypes []string, multiCallType *MultiCallType, writeAddress *common.Address,
-	blockNumber *big.Int, isSimulation bool,
+	ctx context.Context, calls Calls, requireSuccess bool, client Backend, to *common.Address, funcSignature string,
+	txReturnTypes []string, multiCallType *MultiCallType,
+	blockNumber *big.Int, isSimulation bool, overrides *CallOverrides,
 ) Result {
 	return read(
+		ctx,
 		calls,
 		requireSuccess,
 		client,
@@ -204,17 +284,19 @@ func call(
 		funcSignature,
 		txReturnTypes,
 		multiCallType,
-		writeAddress,
 		blockNumber,
 		isSimulation,
+		overrides,
 	)
 }
 
 func callWithFailure(
-	calls CallsWithFailure, client *ethclient.Client, to *common.Address, funcSignature string,
-	txReturnTypes []string, multiCallType *MultiCallType, writeAddress *common.Address, blockNumber *big.Int,
+	ctx context.Context, calls CallsWithFailure, client Backend, to *common.Address, funcSignature string,
+	txReturnTypes []string, multiCallType *MultiCallType, blockNumber *big.Int,
+	overrides *CallOverrides,
 ) Result {
 	return read(
+		ctx,
 		calls,
 		false,
 		client,
@@ -222,16 +304,16 @@ func callWithFailure(
 		funcSignature,
 		txReturnTypes,
 		multiCallType,
-		writeAddress,
 		blockNumber,
 		false,
+		overrides,
 	)
 }
 
 func read(
-	calls CallsInterface, requireSuccess bool, client *ethclient.Client, to *common.Address, funcSignature string,
-	txReturnTypes []string, multiCallType *MultiCallType, writeAddress *common.Address, blockNumber *big.Int,
-	isSimulation bool,
+	ctx context.Context, calls CallsInterface, requireSuccess bool, client Backend, to *common.Address, funcSignature string,
+	txReturnTypes []string, multiCallType *MultiCallType, blockNumber *big.Int,
+	isSimulation bool, overrides *CallOverrides,
 ) Result {
 	arrayfiedCalls, _, err := calls.ToArray(false, false)
 	if err != nil {
@@ -254,6 +336,7 @@ func read(
 	}
 
 	decodedCallResult, decodedAggregatedCallsResultVar, call, err := makeCall(
+		ctx,
 		calls,
 		client,
 		to,
@@ -261,19 +344,28 @@ func read(
 		txReturnTypes,
 		isSimulation,
 		multiCallType,
-		writeAddress,
 		blockNumber,
+		overrides,
 	)
 	if err != nil {
 		return Result{Success: false, Error: err, TxOrCall: call}
 	}
 
+	if isSimulation {
+		simulation, err := newSimulation(calls, decodedAggregatedCallsResultVar)
+		if err != nil {
+			return Result{Success: false, Error: err, TxOrCall: call}
+		}
+
+		return Result{Success: true, Result: simulation, TxOrCall: call}
+	}
+
 	return parseResults(decodedAggregatedCallsResultVar, true, decodedCallResult, call)
 }
 
 func getData(
-	addresses []*common.Address, client *ethclient.Client, to *common.Address,
-	funcSignature string, returnTypes []string, blockNumber *big.Int,
+	ctx context.Context, addresses []*common.Address, client Backend, to *common.Address,
+	funcSignature string, returnTypes []string, blockNumber *big.Int, overrides *CallOverrides,
 ) Result {
 
 	var callData []byte
@@ -287,7 +379,7 @@ func getData(
 		return Result{Success: false, Error: err}
 	}
 
-	encodedCallResult, call, err := readContract(client, &ZERO_ADDRESS, to, callData, blockNumber)
+	encodedCallResult, call, err := readContract(ctx, client, &ZERO_ADDRESS, to, callData, blockNumber, overrides)
 	if err != nil {
 		return Result{Success: false, Error: err, TxOrCall: FromCallToTxOrCall(call, blockNumber)}
 	}
@@ -298,7 +390,7 @@ func getData(
 	}
 
 	if blockNumber == nil {
-		blockNumberUint64, err := client.BlockNumber(context.Background())
+		blockNumberUint64, err := client.BlockNumber(ctx)
 		if err != nil {
 			return Result{Success: false, Error: err, TxOrCall: FromCallToTxOrCall(call, blockNumber)}
 		}
@@ -309,59 +401,52 @@ func getData(
 }
 
 func makeCall(
-	calls CallsInterface, client *ethclient.Client, to *common.Address, callData []byte, txReturnTypes []string,
-	isSimulation bool, multiCallType *MultiCallType, writeAddress *common.Address, blockNumber *big.Int,
+	ctx context.Context, calls CallsInterface, client Backend, to *common.Address, callData []byte, txReturnTypes []string,
+	isSimulation bool, multiCallType *MultiCallType, blockNumber *big.Int,
+	overrides *CallOverrides,
 ) ([]any, []any, TxOrCall, error) {
-	if !true {
-		log.Println(writeAddress)
-	}
-
 	var decodedCallResult []any
-	encodedCallResult, call, err := readContract(client, &ZERO_ADDRESS, to, callData, blockNumber)
+	encodedCallResult, call, err := readContract(ctx, client, &ZERO_ADDRESS, to, callData, blockNumber, overrides)
 	if err != nil && !isSimulation {
 		return nil, nil, TxOrCall{}, err
-	} else if isSimulation {
-		if strings.Contains(err.Error(), "execution reverted") {
-			encodedRevert, ok := parseRevertData(err)
-			if ok {
-				decodedCallResult, err := abi.DecodeWithSignature(
-					"MultiCall__Simulation((bool,bytes,uint256)[])",
-					encodedRevert,
-				)
-				if err != nil {
-					return nil, nil, TxOrCall{}, err
-				}
-				decodedCallResult = decodedCallResult[0].([]any)
-
-				for i, result := range decodedCallResult {
-					decodedCallResult[i].([]any)[1] = common.Bytes2Hex(result.([]any)[1].([]byte))
-				}
-			}
+	} else if isSimulation && err != nil {
+		encodedRevert, ok := parseRevertData(err)
+		if !ok {
+			return nil, nil, TxOrCall{}, err
 		}
-	}
-	if len(encodedCallResult) == 0 {
+
+		decodedCallResult, err = safeDecodeWithSignature(SIMULATION_ERROR_SIGNATURE, encodedRevert)
+		if err != nil {
+			return nil, nil, TxOrCall{}, err
+		}
+	} else if len(encodedCallResult) == 0 {
 		*multiCallType = DEPLOYLESS
-		writeAddress = nil
 	}
 
 	if !isSimulation {
-		decodedCallResult, err = abi.Decode(txReturnTypes, encodedCallResult)
+		decodedCallResult, err = safeDecode(txReturnTypes, encodedCallResult)
 		if err != nil {
 			return nil, nil, TxOrCall{}, err
 		}
 	}
 
-	for len(decodedCallResult) != calls.Len() {
-		decodedCallResult = decodedCallResult[0].([]any)
+	// every aggregate function returns a single array with one entry per call
+	decodedCallResult = decodedCallResult[0].([]any)
+	if len(decodedCallResult) != calls.Len() {
+		return nil, nil, TxOrCall{}, fmt.Errorf("expected %d results, got %d", calls.Len(), len(decodedCallResult))
 	}
 
-	decodedAggregatedCallsResultVar, err := decodeAggregateCallsResult(decodedCallResult, calls)
-	if err != nil {
-		return nil, nil, TxOrCall{}, err
+	// simulation tuples are decoded by newSimulation
+	decodedAggregatedCallsResultVar := decodedCallResult
+	if !isSimulation {
+		decodedAggregatedCallsResultVar, err = decodeAggregateCallsResult(decodedCallResult, calls)
+		if err != nil {
+			return nil, nil, TxOrCall{}, err
+		}
 	}
 
 	if blockNumber == nil {
-		blockNumberUint64, err := client.BlockNumber(context.Background())
+		blockNumberUint64, err := client.BlockNumber(ctx)
 		if err != nil {
 			return nil, nil, TxOrCall{}, err
 		}
@@ -397,23 +482,41 @@ func parseResults(
 func decodeAggregateCallsResult(result []any, calls CallsInterface) ([]any, error) {
 	var decodedResult []any
 	for i, res := range result {
-		returnTypes := calls.GetReturnTypes(i)
-		if returnTypes != nil || len(returnTypes) > 0 {
-			r, ok := res.([]byte)
-			if ok {
-				decodedR, err := abi.Decode(returnTypes, r)
-				if err != nil {
-					return nil, err
-				}
-
-				decodedResult = append(decodedResult, decodedR)
-			} else {
-				decodedResult = append(decodedResult, res.([]any))
+		r, ok := res.([]byte)
+		if ok {
+			decodedR, err := decodeReturnData(calls.GetReturnTypes(i), r)
+			if err != nil {
+				return nil, err
 			}
+
+			decodedResult = append(decodedResult, decodedR)
 		} else {
-			decodedResult = append(decodedResult, res.([]any))
+			tuple := res.([]any)
+			if err := decodeTryResult(i, calls.GetReturnTypes(i), tuple); err != nil {
+				return nil, err
+			}
+
+			decodedResult = append(decodedResult, tuple)
 		}
 	}
 
 	return decodedResult, nil
 }
+
+// decodeTryResult decodes in place the (success, returnData) tuple of the i-th call. The
+// return data of a failed call is replaced by its decoded *CallError.
+func decodeTryResult(i int, returnTypes []string, tuple []any) error {
+	returnData, _ := tuple[1].([]byte)
+	if success, _ := tuple[0].(bool); !success {
+		tuple[1] = newCallError(i, returnData)
+		return nil
+	}
+
+	decoded, err := decodeReturnData(returnTypes, returnData)
+	if err != nil {
+		return fmt.Errorf("error decoding call %d: %w", i, err)
+	}
+	tuple[1] = decoded
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package multicall
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// emptyBytesArrayCode returns abi.encode(new bytes[](0)): PUSH1 0x20 PUSH1 0 MSTORE PUSH1 0x40 PUSH1 0 RETURN.
+var emptyBytesArrayCode = common.FromHex("0x602060005260406000f3")
+
+func newTestWriteMultiCall(t *testing.T, backend *evmBackend) *MultiCall {
+	t.Helper()
+
+	signer, err := NewSigner(testPrivateKey)
+	if err != nil {
+		t.Fatalf("error creating signer: %v", err)
+	}
+	backend.setBalance(*signer.GetAddress(), big.NewInt(1e18))
+
+	address := common.HexToAddress("0x5555")
+	backend.setCode(address, emptyBytesArrayCode)
+
+	return &MultiCall{
+		MultiCallType: GENERAL,
+		WriteAddress:  &address,
+		ReadAddress:   &address,
+		Signer:        &signer,
+	}
+}
+
+func TestAggregateCallsNoSend(t *testing.T) {
+	backend := newEVMBackend(t)
+	mcall := newTestWriteMultiCall(t, backend)
+	calls := newRawCalls([]byte{0x01})
+	nonce := uint64(7)
+
+	result := mcall.AggregateCalls(calls, backend, nil, false, &TxOptions{
+		NoSend:   true,
+		Nonce:    &nonce,
+		GasLimit: 60_000,
+		Value:    big.NewInt(3),
+	})
+	if !result.Success {
+		t.Fatalf("write failed: %v", result.Error)
+	}
+
+	if len(backend.sent) != 0 {
+		t.Errorf("expected no transaction to be sent, got %d", len(backend.sent))
+	}
+	if result.TxOrCall.Nonce != nonce || result.TxOrCall.Gas != 60_000 {
+		t.Errorf("options not reflected in TxOrCall: %s", result.TxOrCall.String())
+	}
+	if result.TxOrCall.Value.Cmp(big.NewInt(3)) != 0 {
+		t.Errorf("expected value 3, got %s", result.TxOrCall.Value)
+	}
+}
+
+func TestAggregateCallsSends(t *testing.T) {
+	backend := newEVMBackend(t)
+	mcall := newTestWriteMultiCall(t, backend)
+	calls := newRawCalls([]byte{0x01})
+
+	result := mcall.AggregateCalls(calls, backend, nil, false, nil)
+	if !result.Success {
+		t.Fatalf("write failed: %v", result.Error)
+	}
+
+	if len(backend.sent) != 1 {
+		t.Fatalf("expected 1 transaction to be sent, got %d", len(backend.sent))
+	}
+	if result.TxOrCall.GasFeeCap == nil || result.TxOrCall.GasTipCap == nil {
+		t.Errorf("expected fee caps in TxOrCall: %s", result.TxOrCall.String())
+	}
+}
+
+func TestBuildSignAndBroadcast(t *testing.T) {
+	backend := newEVMBackend(t)
+	mcall := newTestWriteMultiCall(t, backend)
+	calls := newRawCalls([]byte{0x01})
+
+	tx, err := mcall.BuildAggregateCalls(calls, backend, nil)
+	if err != nil {
+		t.Fatalf("error building transaction: %v", err)
+	}
+	if *tx.To() != *mcall.WriteAddress {
+		t.Errorf("expected transaction to %s, got %s", mcall.WriteAddress, tx.To())
+	}
+
+	rawTx, err := mcall.SignTransaction(tx, backend)
+	if err != nil {
+		t.Fatalf("error signing transaction: %v", err)
+	}
+	if len(backend.sent) != 0 {
+		t.Fatalf("expected no transaction to be sent before broadcasting")
+	}
+
+	result := mcall.Broadcast(rawTx, backend)
+	if !result.Success {
+		t.Fatalf("broadcast failed: %v", result.Error)
+	}
+	receipt := result.Result.(*types.Receipt)
+	if len(backend.sent) != 1 || backend.sent[0].Hash() != receipt.TxHash {
+		t.Errorf("expected the signed transaction to be sent")
+	}
+	if result.TxOrCall.From != *(*mcall.Signer).GetAddress() {
+		t.Errorf("expected sender %s, got %s", (*mcall.Signer).GetAddress(), result.TxOrCall.From)
+	}
+}
+
+func TestBuildWithoutSigner(t *testing.T) {
+	backend := newEVMBackend(t)
+	mcall := newTestWriteMultiCall(t, backend)
+	mcall.Signer = nil
+	calls := newRawCalls([]byte{0x01})
+
+	if _, err := mcall.BuildAggregateCalls(calls, backend, nil); err == nil {
+		t.Errorf("expected error without signer or sender")
+	}
+
+	from := common.HexToAddress("0x1111")
+	if _, err := mcall.BuildAggregateCalls(calls, backend, &TxOptions{From: &from}); err != nil {
+		t.Errorf("error building transaction with explicit sender: %v", err)
+	}
+}
+
+// countingSigner counts the transactions it signs.
+type countingSigner struct {
+	SignerInterface
+	signed int
+}
+
+func (s *countingSigner) SignTx(tx *types.Transaction, chainId *big.Int) (*types.Transaction, error) {
+	s.signed++
+	return s.SignerInterface.SignTx(tx, chainId)
+}
+
+func TestAggregateCallsSimulatesBeforeSigning(t *testing.T) {
+	backend := newEVMBackend(t)
+	mcall := newTestWriteMultiCall(t, backend)
+	signer := &countingSigner{SignerInterface: *mcall.Signer}
+	var signerInterface SignerInterface = signer
+	mcall.Signer = &signerInterface
+
+	backend.setCode(*mcall.WriteAddress, revertCode)
+	result := mcall.AggregateCalls(newRawCalls([]byte{0x01}), backend, nil, false, &TxOptions{GasLimit: 60_000})
+	if result.Success {
+		t.Fatalf("expected the simulation to fail")
+	}
+	if signer.signed != 0 {
+		t.Errorf("expected no signature of a failing transaction, got %d", signer.signed)
+	}
+
+	backend.setCode(*mcall.WriteAddress, emptyBytesArrayCode)
+	result = mcall.AggregateCalls(newRawCalls([]byte{0x01}), backend, nil, false, nil)
+	if !result.Success || signer.signed != 1 {
+		t.Errorf("expected one signature, got %d (%v)", signer.signed, result.Error)
+	}
+}
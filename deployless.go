@@ -7,15 +7,14 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/omnes-tech/abi"
 )
 
 type CallType uint8
 
+// call types of DEPLOYLESS_MULTICALL_BYTECODE, in the order of its enum
 const (
 	SIMULATE_CALL = iota
-	SIMULATE_DELEGATE_CALL
 	STATIC_CALL
 	TRY_STATIC_CALL
 	TRY_STATIC_CALL2
@@ -30,42 +29,38 @@ type CallsWithRequireSuccess struct {
 	RequireSuccess bool
 }
 
-func deploylessSimulation(calls Calls, client *ethclient.Client, blockNumber *big.Int) Result {
+func deploylessSimulation(
+	ctx context.Context, calls Calls, client Backend, blockNumber *big.Int, overrides *CallOverrides,
+) Result {
 	arrayfiedCalls, _, err := calls.ToArray(true, false)
 	if err != nil {
 		return Result{Success: false, Error: err}
 	}
 
 	_, txOrCall, err := makeDeploylessCall(
+		ctx,
 		arrayfiedCalls,
 		false,
 		SIMULATE_CALL,
 		client,
 		[]string{"(address,bytes,uint256)[]"},
 		blockNumber,
+		overrides,
 	)
 	if err != nil {
 		if strings.Contains(err.Error(), "execution reverted") {
 			encodedRevert, ok := parseRevertData(err)
 			if ok {
-				decodedRevert, err := abi.DecodeWithSignature(
-					"MultiCall__Simulation((bool,bytes,uint256)[])",
-					encodedRevert,
-				)
+				decodedRevert, err := safeDecodeWithSignature(SIMULATION_ERROR_SIGNATURE, encodedRevert)
 				if err != nil {
 					return Result{Success: false, Error: err, TxOrCall: txOrCall}
 				}
-				decodedRevert = decodedRevert[0].([]any)
-
-				for i, result := range decodedRevert {
-					decodedRevert[i].([]any)[1] = common.Bytes2Hex(result.([]any)[1].([]byte))
+				simulation, err := newSimulation(calls, decodedRevert[0].([]any))
+				if err != nil {
+					return Result{Success: false, Error: err, TxOrCall: txOrCall}
 				}
 
-				return Result{
-					Success:  true,
-					Result:   decodedRevert,
-					TxOrCall: txOrCall,
-				}
+				return Result{Success: true, Result: simulation, TxOrCall: txOrCall}
 			}
 		}
 		return Result{Success: false, Error: err, TxOrCall: txOrCall}
@@ -74,19 +69,23 @@ func deploylessSimulation(calls Calls, client *ethclient.Client, blockNumber *bi
 	return Result{Success: false, Error: fmt.Errorf("call did not returned simulation result"), TxOrCall: txOrCall}
 }
 
-func deploylessAggregateStatic(calls Calls, client *ethclient.Client, blockNumber *big.Int) Result {
+func deploylessAggregateStatic(
+	ctx context.Context, calls Calls, client Backend, blockNumber *big.Int, overrides *CallOverrides,
+) Result {
 	arrayfiedCalls, _, err := calls.ToArray(false, false)
 	if err != nil {
 		return Result{Success: false, Error: err}
 	}
 
 	rawResponse, txOrCall, err := makeDeploylessCall(
+		ctx,
 		arrayfiedCalls,
 		false,
 		STATIC_CALL,
 		client,
 		[]string{"(address,bytes)[]"},
 		blockNumber,
+		overrides,
 	)
 	if err != nil {
 		return Result{Success: false, Error: err, TxOrCall: txOrCall}
@@ -100,7 +99,7 @@ func deploylessAggregateStatic(calls Calls, client *ethclient.Client, blockNumbe
 
 	var result []any
 	for i, call := range calls {
-		result_i, err := abi.Decode(call.ReturnTypes, resultArgs[i].([]byte))
+		result_i, err := decodeReturnData(call.ReturnTypes, resultArgs[i].([]byte))
 		if err != nil {
 			return Result{Success: false, Error: err, TxOrCall: txOrCall}
 		}
@@ -112,7 +111,7 @@ func deploylessAggregateStatic(calls Calls, client *ethclient.Client, blockNumbe
 }
 
 func deploylessTryAggregateStatic(
-	calls Calls, requireSuccess bool, client *ethclient.Client, blockNumber *big.Int,
+	ctx context.Context, calls Calls, requireSuccess bool, client Backend, blockNumber *big.Int, overrides *CallOverrides,
 ) Result {
 	arrayfiedCalls, _, err := calls.ToArray(false, false)
 	if err != nil {
@@ -120,12 +119,14 @@ func deploylessTryAggregateStatic(
 	}
 
 	rawResponse, txOrCall, err := makeDeploylessCall(
+		ctx,
 		arrayfiedCalls,
 		requireSuccess,
 		TRY_STATIC_CALL,
 		client,
 		[]string{"(address,bytes)[]", "bool"},
 		blockNumber,
+		overrides,
 	)
 	if err != nil {
 		return Result{Success: false, Error: err, TxOrCall: txOrCall}
@@ -139,7 +140,7 @@ func deploylessTryAggregateStatic(
 
 	var result []any
 	for i, call := range calls {
-		resultArgs[i].([]any)[1], err = abi.Decode(call.ReturnTypes, resultArgs[i].([]any)[1].([]byte))
+		err = decodeTryResult(i, call.ReturnTypes, resultArgs[i].([]any))
 		if err != nil {
 			return Result{Success: false, Error: err, TxOrCall: txOrCall}
 		}
@@ -151,7 +152,7 @@ func deploylessTryAggregateStatic(
 }
 
 func deploylessTryAggregateStatic3(
-	calls CallsWithFailure, client *ethclient.Client, blockNumber *big.Int,
+	ctx context.Context, calls CallsWithFailure, client Backend, blockNumber *big.Int, overrides *CallOverrides,
 ) Result {
 	arrayfiedCalls, _, err := calls.ToArray(false, false)
 	if err != nil {
@@ -159,12 +160,14 @@ func deploylessTryAggregateStatic3(
 	}
 
 	rawResponse, txOrCall, err := makeDeploylessCall(
+		ctx,
 		arrayfiedCalls,
 		false,
 		TRY_STATIC_CALL2,
 		client,
 		[]string{"(address,bytes,bool)[]"},
 		blockNumber,
+		overrides,
 	)
 	if err != nil {
 		return Result{Success: false, Error: err, TxOrCall: txOrCall}
@@ -178,7 +181,7 @@ func deploylessTryAggregateStatic3(
 
 	var result []any
 	for i, call := range calls {
-		resultArgs[i].([]any)[1], err = abi.Decode(call.ReturnTypes, resultArgs[i].([]any)[1].([]byte))
+		err = decodeTryResult(i, call.ReturnTypes, resultArgs[i].([]any))
 		if err != nil {
 			return Result{Success: false, Error: err, TxOrCall: txOrCall}
 		}
@@ -190,102 +193,84 @@ func deploylessTryAggregateStatic3(
 }
 
 func deploylessGetCodeLengths(
-	addresses []*common.Address, client *ethclient.Client, blockNumber *big.Int,
+	ctx context.Context, addresses []*common.Address, client Backend, blockNumber *big.Int, overrides *CallOverrides,
 ) Result {
 
 	rawResponse, txOrCall, err := makeDeploylessCall(
-		toAnyArray(addresses), false, CODE_LENGTH, client, []string{"address[]"}, blockNumber,
+		ctx, toAnyArray(addresses), false, CODE_LENGTH, client, []string{"address[]"}, blockNumber, overrides,
 	)
 	if err != nil {
 		return Result{Success: false, Error: err, TxOrCall: txOrCall}
 	}
 
-	resultArgs, err := abi.Decode([]string{"uint256[]"}, common.Hex2Bytes(rawResponse[2:]))
+	resultArgs, err := safeDecode([]string{"uint256[]"}, common.Hex2Bytes(rawResponse[2:]))
 	if err != nil {
 		return Result{Success: false, Error: err, TxOrCall: txOrCall}
 	}
-	resultArgs = resultArgs[0].([]any)
 
-	return Result{Success: true, Result: resultArgs, TxOrCall: txOrCall}
+	return bigIntsResult(Result{Success: true, Result: resultArgs, TxOrCall: txOrCall}, addresses)
 }
 
 func deploylessGetBalances(
-	addresses []*common.Address, client *ethclient.Client, blockNumber *big.Int,
+	ctx context.Context, addresses []*common.Address, client Backend, blockNumber *big.Int, overrides *CallOverrides,
 ) Result {
 
 	rawResponse, txOrCall, err := makeDeploylessCall(
-		toAnyArray(addresses), false, BALANCES, client, []string{"address[]"}, blockNumber,
+		ctx, toAnyArray(addresses), false, BALANCES, client, []string{"address[]"}, blockNumber, overrides,
 	)
 	if err != nil {
 		return Result{Success: false, Error: err, TxOrCall: txOrCall}
 	}
 
-	resultArgs, err := abi.Decode([]string{"uint256[]"}, common.Hex2Bytes(rawResponse[2:]))
+	resultArgs, err := safeDecode([]string{"uint256[]"}, common.Hex2Bytes(rawResponse[2:]))
 	if err != nil {
 		return Result{Success: false, Error: err, TxOrCall: txOrCall}
 	}
-	resultArgs = resultArgs[0].([]any)
 
-	return Result{Success: true, Result: resultArgs, TxOrCall: txOrCall}
+	return bigIntsResult(Result{Success: true, Result: resultArgs, TxOrCall: txOrCall}, addresses)
 }
 
 func deploylessGetAddressesData(
-	addresses []*common.Address, client *ethclient.Client, blockNumber *big.Int,
+	ctx context.Context, addresses []*common.Address, client Backend, blockNumber *big.Int, overrides *CallOverrides,
 ) Result {
 
 	rawResponse, txOrCall, err := makeDeploylessCall(
-		toAnyArray(addresses), false, ADDRESSES_DATA, client, []string{"address[]"}, blockNumber,
+		ctx, toAnyArray(addresses), false, ADDRESSES_DATA, client, []string{"address[]"}, blockNumber, overrides,
 	)
 	if err != nil {
 		return Result{Success: false, Error: err, TxOrCall: txOrCall}
 	}
 
-	resultArgs, err := abi.Decode([]string{"uint256[]", "uint256[]"}, common.Hex2Bytes(rawResponse[2:]))
+	resultArgs, err := safeDecode([]string{"uint256[]", "uint256[]"}, common.Hex2Bytes(rawResponse[2:]))
 	if err != nil {
 		return Result{Success: false, Error: err, TxOrCall: txOrCall}
 	}
 
-	var result [][]any
-	for i := range addresses {
-		result = append(result, []any{resultArgs[0].([]any)[i], resultArgs[1].([]any)[i]})
-	}
-
-	return Result{Success: true, Result: result, TxOrCall: txOrCall}
+	return addressesInfoResult(Result{Success: true, Result: resultArgs, TxOrCall: txOrCall}, addresses)
 }
 
-func deploylessGetChainData(client *ethclient.Client, blockNumber *big.Int) Result {
+func deploylessGetChainData(
+	ctx context.Context, client Backend, blockNumber *big.Int, overrides *CallOverrides,
+) Result {
 
 	rawResponse, txOrCall, err := makeDeploylessCall(
-		nil, false, CHAIN_DATA, client, nil, blockNumber,
+		ctx, nil, false, CHAIN_DATA, client, nil, blockNumber, overrides,
 	)
 	if err != nil {
 		return Result{Success: false, Error: err, TxOrCall: txOrCall}
 	}
 
-	resultArgs, err := abi.Decode(
-		[]string{
-			"uint256",
-			"uint256",
-			"bytes32",
-			"uint256",
-			"address",
-			"uint256",
-			"uint256",
-			"uint256",
-			"uint256",
-		},
-		common.Hex2Bytes(rawResponse[2:]),
-	)
+	resultArgs, err := safeDecode(CHAIN_DATA_RETURN_TYPES, common.Hex2Bytes(rawResponse[2:]))
 	if err != nil {
 		return Result{Success: false, Error: err, TxOrCall: txOrCall}
 	}
 
-	return Result{Success: true, Result: resultArgs, TxOrCall: txOrCall}
+	return chainInfoResult(Result{Success: true, Result: resultArgs, TxOrCall: txOrCall})
 }
 
 func makeDeploylessCall(
-	params []any, requireSuccess bool, callType CallType,
-	client *ethclient.Client, typeStrs []string, blockNumber *big.Int,
+	ctx context.Context, params []any, requireSuccess bool, callType CallType,
+	client Backend, typeStrs []string, blockNumber *big.Int, overrides *CallOverrides,
 ) (string, TxOrCall, error) {
 	var encoded []byte
 	var err error
@@ -310,24 +295,16 @@ func makeDeploylessCall(
 
 	data := DEPLOYLESS_MULTICALL_BYTECODE + common.Bytes2Hex(encodedParamsToDeploy)
 
-	var blockNumberStr string
-	if blockNumber != nil {
-		blockNumberStr = blockNumber.String()
-	} else {
-		blockNumberStr = "latest"
-	}
-
-	var rawResponse string
-	err = client.Client().CallContext(context.Background(), &rawResponse, "eth_call", map[string]interface{}{
+	rawResponse, err := ethCall(ctx, client, map[string]interface{}{
 		"to":   nil, // This is a deployless call, so `to` is `nil`
 		"data": data,
-	}, blockNumberStr)
+	}, blockNumber, overrides)
 	if err != nil {
 		return rawResponse, TxOrCall{}, fmt.Errorf("error making deployless call: %w, with data: %s", err, data)
 	}
 
 	if blockNumber == nil {
-		blockNumberUint64, err := client.BlockNumber(context.Background())
+		blockNumberUint64, err := client.BlockNumber(ctx)
 		if err != nil {
 			return rawResponse, TxOrCall{}, fmt.Errorf("error getting block number: %w", err)
 		}
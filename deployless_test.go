@@ -0,0 +1,107 @@
+package multicall
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TestDeploylessCallTypes pins the call types to the enum of DEPLOYLESS_MULTICALL_BYTECODE,
+// a shifted value running every deployless read in another mode.
+func TestDeploylessCallTypes(t *testing.T) {
+	callTypes := []int{
+		SIMULATE_CALL, STATIC_CALL, TRY_STATIC_CALL, TRY_STATIC_CALL2, CODE_LENGTH, BALANCES, ADDRESSES_DATA, CHAIN_DATA,
+	}
+	for i, callType := range callTypes {
+		if callType != i {
+			t.Errorf("expected call type %d at position %d of the bytecode enum, got %d", i, i, callType)
+		}
+	}
+}
+
+func TestDeploylessReads(t *testing.T) {
+	backend := newEVMBackend(t)
+	target := common.HexToAddress("0x4444")
+	backend.setCode(target, echoCode)
+	backend.setBalance(target, big.NewInt(5))
+	mcall := &MultiCall{MultiCallType: DEPLOYLESS}
+	calls := NewCalls([]common.Address{target, target}, []string{"", ""}, nil, [][]byte{{0xab}, {0xcd}}, nil, nil)
+
+	aggregate := mcall.AggregateStatic(calls, backend, nil)
+	if !aggregate.Success || !reflect.DeepEqual(aggregate.Result, []any{[]byte{0xab}, []byte{0xcd}}) {
+		t.Errorf("unexpected aggregate result %v (%v)", aggregate.Result, aggregate.Error)
+	}
+
+	tryAggregate := mcall.TryAggregateStatic(calls, false, backend, nil)
+	expected := []any{[]any{true, []byte{0xab}}, []any{true, []byte{0xcd}}}
+	if !tryAggregate.Success || !reflect.DeepEqual(tryAggregate.Result, expected) {
+		t.Errorf("unexpected try aggregate result %v (%v)", tryAggregate.Result, tryAggregate.Error)
+	}
+
+	balances := mcall.Balances([]*common.Address{&target}, backend, nil)
+	if !balances.Success || balances.Result.([]*big.Int)[0].Int64() != 5 {
+		t.Errorf("unexpected balances %v (%v)", balances.Result, balances.Error)
+	}
+
+	codeLengths := mcall.CodeLengths([]*common.Address{&target}, backend, nil)
+	if !codeLengths.Success || codeLengths.Result.([]*big.Int)[0].Int64() != int64(len(echoCode)) {
+		t.Errorf("unexpected code lengths %v (%v)", codeLengths.Result, codeLengths.Error)
+	}
+
+	empty := common.HexToAddress("0x5555")
+	addressesData := mcall.AddressesData([]*common.Address{&target, &empty}, backend, nil)
+	expectedInfos := []AddressInfo{
+		{Address: target, Balance: big.NewInt(5), CodeLength: big.NewInt(int64(len(echoCode))), IsContract: true},
+		{Address: empty, Balance: big.NewInt(0), CodeLength: big.NewInt(0)},
+	}
+	if !addressesData.Success || !reflect.DeepEqual(normalizeInfos(addressesData.Result), expectedInfos) {
+		t.Errorf("unexpected addresses data %+v (%v)", addressesData.Result, addressesData.Error)
+	}
+
+	chainData := mcall.ChainData(backend, nil)
+	if !chainData.Success {
+		t.Fatalf("unexpected chain data error %v", chainData.Error)
+	}
+	chainInfo := chainData.Result.(ChainInfo)
+	if chainInfo.ChainID.Cmp(backend.chainID) != 0 || chainInfo.BlockNumber == nil || chainInfo.GasLimit.Sign() <= 0 {
+		t.Errorf("unexpected chain info %+v", chainInfo)
+	}
+}
+
+func TestAddressesInfoResult(t *testing.T) {
+	first, second := common.HexToAddress("0x1"), common.HexToAddress("0x2")
+	addresses := []*common.Address{&first, &second}
+
+	// shape returned by getAddressesData(address[]) of the Omnes contract
+	decoded := []any{[]any{big.NewInt(1), big.NewInt(2)}, []any{big.NewInt(0), big.NewInt(10)}}
+	result := addressesInfoResult(Result{Success: true, Result: decoded}, addresses)
+	expected := []AddressInfo{
+		{Address: first, Balance: big.NewInt(1), CodeLength: big.NewInt(0)},
+		{Address: second, Balance: big.NewInt(2), CodeLength: big.NewInt(10), IsContract: true},
+	}
+	if !result.Success || !reflect.DeepEqual(result.Result, expected) {
+		t.Errorf("unexpected addresses info %+v (%v)", result.Result, result.Error)
+	}
+
+	balances := bigIntsResult(Result{Success: true, Result: []any{[]any{big.NewInt(1), big.NewInt(2)}}}, addresses)
+	if !balances.Success || !reflect.DeepEqual(balances.Result, []*big.Int{big.NewInt(1), big.NewInt(2)}) {
+		t.Errorf("unexpected balances %+v (%v)", balances.Result, balances.Error)
+	}
+
+	if result := bigIntsResult(Result{Success: true, Result: []any{[]any{big.NewInt(1)}}}, addresses); result.Success {
+		t.Errorf("expected an error for a missing value")
+	}
+}
+
+// normalizeInfos resets the internal representation of zero big.Ints for DeepEqual.
+func normalizeInfos(result any) []AddressInfo {
+	infos, _ := result.([]AddressInfo)
+	for i := range infos {
+		infos[i].Balance = new(big.Int).Set(infos[i].Balance)
+		infos[i].CodeLength = new(big.Int).Set(infos[i].CodeLength)
+	}
+
+	return infos
+}
@@ -0,0 +1,214 @@
+// Package erc20 reads ERC-20 metadata, balances and allowances of many tokens in a
+// single multicall, tolerating tokens that do not follow the standard.
+package erc20
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/omnes-tech/multicall"
+)
+
+// Token is the metadata of an ERC-20 token. Fields of methods that reverted or returned
+// undecodable data keep their zero value and the methods are listed in Missing.
+type Token struct {
+	Address     common.Address
+	Name        string
+	Symbol      string
+	Decimals    uint8
+	TotalSupply *big.Int
+
+	Missing []string
+}
+
+// HasDecimals reports whether decimals() was read, as a token without it has no decimals
+// rather than zero.
+func (t Token) HasDecimals() bool {
+	for _, method := range t.Missing {
+		if method == "decimals()" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Allowance is the owner and spender of an allowance.
+type Allowance struct {
+	Owner   common.Address
+	Spender common.Address
+}
+
+// metadataMethods are the methods read for every token, in the order of the calls.
+var metadataMethods = []string{"name()", "symbol()", "decimals()", "totalSupply()"}
+
+// Metadata reads the name, symbol, decimals and total supply of the tokens.
+func Metadata(
+	m *multicall.MultiCall, tokens []common.Address, client multicall.Backend, blockNumber *big.Int,
+) ([]Token, multicall.TxOrCall, error) {
+	return MetadataContext(context.Background(), m, tokens, client, blockNumber)
+}
+
+// MetadataContext is like Metadata but runs with the given context.
+func MetadataContext(
+	ctx context.Context, m *multicall.MultiCall, tokens []common.Address, client multicall.Backend, blockNumber *big.Int,
+) ([]Token, multicall.TxOrCall, error) {
+	decoders := []multicall.Decoder[any]{decodeString, decodeString, decodeDecimals, decodeUint256}
+
+	var calls []multicall.TypedCall[any]
+	for _, token := range tokens {
+		for i, method := range metadataMethods {
+			calls = append(calls, multicall.NewTypedCall(token, method, nil, decoders[i]))
+		}
+	}
+
+	outcomes, txOrCall, err := multicall.TryAggregateContext(ctx, m, calls, client, blockNumber)
+	if err != nil {
+		return nil, txOrCall, err
+	}
+
+	return newTokens(tokens, outcomes), txOrCall, nil
+}
+
+// Balances reads the balance of every holder for every token. The table is indexed by
+// token then holder, and the balances that could not be read are nil.
+func Balances(
+	m *multicall.MultiCall, tokens []common.Address, holders []common.Address,
+	client multicall.Backend, blockNumber *big.Int,
+) ([][]*big.Int, multicall.TxOrCall, error) {
+	return BalancesContext(context.Background(), m, tokens, holders, client, blockNumber)
+}
+
+// BalancesContext is like Balances but runs with the given context.
+func BalancesContext(
+	ctx context.Context, m *multicall.MultiCall, tokens []common.Address, holders []common.Address,
+	client multicall.Backend, blockNumber *big.Int,
+) ([][]*big.Int, multicall.TxOrCall, error) {
+	argss := make([][]any, len(holders))
+	for i := range holders {
+		argss[i] = []any{&holders[i]}
+	}
+
+	return readTable(ctx, m, tokens, "balanceOf(address)", argss, client, blockNumber)
+}
+
+// Allowances reads every allowance for every token. The table is indexed by token then
+// allowance, and the allowances that could not be read are nil.
+func Allowances(
+	m *multicall.MultiCall, tokens []common.Address, allowances []Allowance,
+	client multicall.Backend, blockNumber *big.Int,
+) ([][]*big.Int, multicall.TxOrCall, error) {
+	return AllowancesContext(context.Background(), m, tokens, allowances, client, blockNumber)
+}
+
+// AllowancesContext is like Allowances but runs with the given context.
+func AllowancesContext(
+	ctx context.Context, m *multicall.MultiCall, tokens []common.Address, allowances []Allowance,
+	client multicall.Backend, blockNumber *big.Int,
+) ([][]*big.Int, multicall.TxOrCall, error) {
+	argss := make([][]any, len(allowances))
+	for i := range allowances {
+		argss[i] = []any{&allowances[i].Owner, &allowances[i].Spender}
+	}
+
+	return readTable(ctx, m, tokens, "allowance(address,address)", argss, client, blockNumber)
+}
+
+// readTable calls funcSignature on every token with every args and returns the uint256
+// results indexed by token then args.
+func readTable(
+	ctx context.Context, m *multicall.MultiCall, tokens []common.Address, funcSignature string, argss [][]any,
+	client multicall.Backend, blockNumber *big.Int,
+) ([][]*big.Int, multicall.TxOrCall, error) {
+	calls := make([]multicall.TypedCall[*big.Int], 0, len(tokens)*len(argss))
+	for _, token := range tokens {
+		for _, args := range argss {
+			calls = append(calls, multicall.NewTypedCall(token, funcSignature, args, multicall.DecodeAs[*big.Int]("uint256")))
+		}
+	}
+
+	outcomes, txOrCall, err := multicall.TryAggregateContext(ctx, m, calls, client, blockNumber)
+	if err != nil {
+		return nil, txOrCall, err
+	}
+
+	return newTable(len(tokens), len(argss), outcomes), txOrCall, nil
+}
+
+func newTokens(tokens []common.Address, outcomes []multicall.Outcome[any]) []Token {
+	result := make([]Token, len(tokens))
+	for i, token := range tokens {
+		result[i].Address = token
+		for j, method := range metadataMethods {
+			outcome := outcomes[i*len(metadataMethods)+j]
+			if !outcome.Success {
+				result[i].Missing = append(result[i].Missing, method)
+				continue
+			}
+
+			switch method {
+			case "name()":
+				result[i].Name = outcome.Value.(string)
+			case "symbol()":
+				result[i].Symbol = outcome.Value.(string)
+			case "decimals()":
+				result[i].Decimals = outcome.Value.(uint8)
+			case "totalSupply()":
+				result[i].TotalSupply = outcome.Value.(*big.Int)
+			}
+		}
+	}
+
+	return result
+}
+
+func newTable(rows int, columns int, outcomes []multicall.Outcome[*big.Int]) [][]*big.Int {
+	table := make([][]*big.Int, rows)
+	for i := range table {
+		table[i] = make([]*big.Int, columns)
+		for j := range table[i] {
+			if outcome := outcomes[i*columns+j]; outcome.Success {
+				table[i][j] = outcome.Value
+			}
+		}
+	}
+
+	return table
+}
+
+// decodeString decodes a string, or a bytes32 right padded with zeros as returned by
+// tokens like MKR.
+func decodeString(returnData []byte) (any, error) {
+	if len(returnData) == 32 {
+		value := string(bytes.TrimRight(returnData, "\x00"))
+		if !utf8.ValidString(value) {
+			return nil, fmt.Errorf("invalid bytes32 string 0x%x", returnData)
+		}
+
+		return value, nil
+	}
+
+	return multicall.DecodeAs[string]("string")(returnData)
+}
+
+// decodeDecimals decodes decimals, returned as uint8 by the standard but as uint256 by
+// some tokens.
+func decodeDecimals(returnData []byte) (any, error) {
+	decimals, err := multicall.DecodeAs[*big.Int]("uint256")(returnData)
+	if err != nil {
+		return nil, err
+	}
+	if !decimals.IsUint64() || decimals.Uint64() > 255 {
+		return nil, fmt.Errorf("invalid decimals %s", decimals)
+	}
+
+	return uint8(decimals.Uint64()), nil
+}
+
+func decodeUint256(returnData []byte) (any, error) {
+	return multicall.DecodeAs[*big.Int]("uint256")(returnData)
+}
@@ -0,0 +1,86 @@
+package erc20
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/omnes-tech/abi"
+	"github.com/omnes-tech/multicall"
+)
+
+func TestDecoders(t *testing.T) {
+	encodedString, err := abi.Encode([]string{"string"}, "Dai Stablecoin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		decoder    multicall.Decoder[any]
+		returnData []byte
+		expected   any
+		fails      bool
+	}{
+		{"string", decodeString, encodedString, "Dai Stablecoin", false},
+		{"bytes32 string", decodeString, common.RightPadBytes([]byte("MKR"), 32), "MKR", false},
+		{"empty string", decodeString, nil, nil, true},
+		{"decimals", decodeDecimals, common.LeftPadBytes([]byte{18}, 32), uint8(18), false},
+		{"decimals overflow", decodeDecimals, common.LeftPadBytes([]byte{1, 0}, 32), nil, true},
+		{"total supply", decodeUint256, common.LeftPadBytes([]byte{1, 0}, 32), big.NewInt(256), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := test.decoder(test.returnData)
+			if test.fails {
+				if err == nil {
+					t.Errorf("expected an error, got %v", value)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(value, test.expected) {
+				t.Errorf("expected %v, got %v (%v)", test.expected, value, err)
+			}
+		})
+	}
+}
+
+var errReverted = errors.New("execution reverted")
+
+func TestNewTokens(t *testing.T) {
+	tokens := []common.Address{common.HexToAddress("0x1"), common.HexToAddress("0x2")}
+	outcomes := []multicall.Outcome[any]{
+		{Success: true, Value: "Token"}, {Success: true, Value: "TKN"},
+		{Success: true, Value: uint8(6)}, {Success: true, Value: big.NewInt(100)},
+		{Err: errReverted}, {Success: true, Value: "OLD"},
+		{Err: errReverted}, {Success: true, Value: big.NewInt(5)},
+	}
+
+	result := newTokens(tokens, outcomes)
+	expected := []Token{
+		{Address: tokens[0], Name: "Token", Symbol: "TKN", Decimals: 6, TotalSupply: big.NewInt(100)},
+		{Address: tokens[1], Symbol: "OLD", TotalSupply: big.NewInt(5), Missing: []string{"name()", "decimals()"}},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+	if !result[0].HasDecimals() || result[1].HasDecimals() {
+		t.Errorf("unexpected HasDecimals")
+	}
+}
+
+func TestNewTable(t *testing.T) {
+	outcomes := []multicall.Outcome[*big.Int]{
+		{Success: true, Value: big.NewInt(1)}, {Success: false},
+		{Success: true, Value: big.NewInt(3)}, {Success: true, Value: big.NewInt(4)},
+	}
+
+	table := newTable(2, 2, outcomes)
+	expected := [][]*big.Int{{big.NewInt(1), nil}, {big.NewInt(3), big.NewInt(4)}}
+	if !reflect.DeepEqual(table, expected) {
+		t.Errorf("expected %v, got %v", expected, table)
+	}
+}
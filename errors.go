@@ -0,0 +1,136 @@
+package multicall
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/omnes-tech/abi"
+)
+
+const ERROR_SIGNATURE = "Error(string)"
+const PANIC_SIGNATURE = "Panic(uint256)"
+
+// PANIC_CODES names the codes of the Panic(uint256) errors raised by the Solidity compiler.
+var PANIC_CODES = map[uint64]string{
+	0x00: "generic compiler panic",
+	0x01: "assertion failed",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "invalid enum conversion",
+	0x22: "incorrectly encoded storage byte array",
+	0x31: "pop on empty array",
+	0x32: "array index out of bounds",
+	0x41: "out of memory",
+	0x51: "call to zero-initialized function",
+}
+
+var errorSignatures = struct {
+	sync.RWMutex
+	bySelector map[string]string
+}{bySelector: map[string]string{
+	string(abi.EncodeSignature("MultiCall__CallFailed(uint256)")):       "MultiCall__CallFailed(uint256)",
+	string(abi.EncodeSignature("MultiCall__StaticCallFailed(uint256)")): "MultiCall__StaticCallFailed(uint256)",
+}}
+
+// RegisterErrors registers custom error signatures, e.g. "InsufficientBalance(uint256,uint256)",
+// used to decode the revert data of failed calls.
+func RegisterErrors(signatures ...string) error {
+	errorSignatures.Lock()
+	defer errorSignatures.Unlock()
+
+	for _, signature := range signatures {
+		if _, err := abi.GetSigTypes(signature); err != nil {
+			return fmt.Errorf("invalid error signature %s: %w", signature, err)
+		}
+		errorSignatures.bySelector[string(abi.EncodeSignature(signature))] = signature
+	}
+
+	return nil
+}
+
+// CallError is the decoded revert of a failed call of a batch.
+type CallError struct {
+	Index     int      // index of the call in the batch
+	Data      []byte   // raw revert data
+	Signature string   // signature of the decoded error, empty when unknown
+	Reason    string   // message of Error(string) or name of the Panic(uint256) code
+	PanicCode *big.Int // code of Panic(uint256)
+	Args      []any    // decoded arguments of the error
+}
+
+func (e *CallError) Error() string {
+	switch {
+	case e.Signature == ERROR_SIGNATURE:
+		return fmt.Sprintf("call %d reverted: %s", e.Index, e.Reason)
+	case e.Signature == PANIC_SIGNATURE:
+		return fmt.Sprintf("call %d panicked: %s (0x%x)", e.Index, e.Reason, e.PanicCode)
+	case e.Signature != "":
+		return fmt.Sprintf("call %d reverted with %s%v", e.Index, e.Signature[:strings.Index(e.Signature, "(")], e.Args)
+	case len(e.Data) == 0:
+		return fmt.Sprintf("call %d reverted without data", e.Index)
+	}
+
+	return fmt.Sprintf("call %d reverted with data 0x%s", e.Index, common.Bytes2Hex(e.Data))
+}
+
+// DecodeRevert decodes the revert data of a failed call as Error(string), Panic(uint256)
+// or one of the registered custom errors. Unknown errors only keep their raw data.
+func DecodeRevert(data []byte) *CallError {
+	callError := &CallError{Data: data}
+	if len(data) < 4 {
+		return callError
+	}
+
+	var signature string
+	selector := string(data[:4])
+	switch selector {
+	case string(abi.EncodeSignature(ERROR_SIGNATURE)):
+		signature = ERROR_SIGNATURE
+	case string(abi.EncodeSignature(PANIC_SIGNATURE)):
+		signature = PANIC_SIGNATURE
+	default:
+		errorSignatures.RLock()
+		signature = errorSignatures.bySelector[selector]
+		errorSignatures.RUnlock()
+	}
+	if signature == "" {
+		return callError
+	}
+
+	types, err := abi.GetSigTypes(signature)
+	if err != nil {
+		return callError
+	}
+	args, err := safeDecode(types, data[4:])
+	if err != nil {
+		return callError
+	}
+
+	callError.Signature = signature
+	callError.Args = args
+	switch signature {
+	case ERROR_SIGNATURE:
+		callError.Reason, _ = args[0].(string)
+	case PANIC_SIGNATURE:
+		callError.PanicCode, _ = args[0].(*big.Int)
+		if callError.PanicCode != nil && callError.PanicCode.IsUint64() {
+			callError.Reason = PANIC_CODES[callError.PanicCode.Uint64()]
+		}
+		if callError.Reason == "" {
+			callError.Reason = "unknown panic code"
+		}
+	}
+
+	return callError
+}
+
+// newCallError decodes the revert data of the i-th call of a batch.
+func newCallError(i int, data []byte) *CallError {
+	callError := DecodeRevert(data)
+	callError.Index = i
+
+	return callError
+}
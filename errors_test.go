@@ -0,0 +1,83 @@
+package multicall
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/omnes-tech/abi"
+)
+
+func TestDecodeRevert(t *testing.T) {
+	if err := RegisterErrors("InsufficientBalance(address,uint256)"); err != nil {
+		t.Fatalf("error registering errors: %v", err)
+	}
+
+	encode := func(signature string, args ...any) []byte {
+		data, err := abi.EncodeWithSignature(signature, args...)
+		if err != nil {
+			t.Fatalf("error encoding %s: %v", signature, err)
+		}
+		return data
+	}
+
+	account := common.HexToAddress("0x4444")
+
+	tests := []struct {
+		name      string
+		data      []byte
+		signature string
+		message   string
+	}{
+		{"error string", encode(ERROR_SIGNATURE, "not owner"), ERROR_SIGNATURE, "call 0 reverted: not owner"},
+		{"panic", encode(PANIC_SIGNATURE, big.NewInt(0x11)), PANIC_SIGNATURE,
+			"call 0 panicked: arithmetic underflow or overflow (0x11)"},
+		{"custom error", encode("InsufficientBalance(address,uint256)", &account, big.NewInt(5)),
+			"InsufficientBalance(address,uint256)",
+			"call 0 reverted with InsufficientBalance[0x0000000000000000000000000000000000004444 5]"},
+		{"unknown error", []byte{0xde, 0xad, 0xbe, 0xef}, "", "call 0 reverted with data 0xdeadbeef"},
+		{"empty revert", nil, "", "call 0 reverted without data"},
+		{"malformed error string", []byte{0x08, 0xc3, 0x79, 0xa0, 0x01}, "", "call 0 reverted with data 0x08c379a001"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			callError := DecodeRevert(test.data)
+			if callError.Signature != test.signature {
+				t.Errorf("expected signature %q, got %q", test.signature, callError.Signature)
+			}
+			if callError.Error() != test.message {
+				t.Errorf("expected message %q, got %q", test.message, callError.Error())
+			}
+		})
+	}
+}
+
+func TestDecodeTryResultKeepsFailuresPerCall(t *testing.T) {
+	calls := newRawCalls([]byte{0x01}, []byte{0x02})
+	calls[0].ReturnTypes = []string{"uint256"}
+	calls[1].ReturnTypes = []string{"uint256"}
+
+	revert, err := abi.EncodeWithSignature(ERROR_SIGNATURE, "boom")
+	if err != nil {
+		t.Fatalf("error encoding revert: %v", err)
+	}
+
+	decoded, err := decodeAggregateCallsResult([]any{
+		[]any{true, encodeUint256(t, 9)},
+		[]any{false, revert},
+	}, calls)
+	if err != nil {
+		t.Fatalf("expected failed call not to fail the batch: %v", err)
+	}
+
+	if value := decoded[0].([]any)[1].([]any)[0].(*big.Int); value.Int64() != 9 {
+		t.Errorf("expected 9, got %s", value)
+	}
+
+	var callError *CallError
+	if !errors.As(decoded[1].([]any)[1].(error), &callError) || callError.Index != 1 || callError.Reason != "boom" {
+		t.Errorf("expected call error for call 1, got %v", decoded[1].([]any)[1])
+	}
+}
@@ -0,0 +1,22 @@
+package multicall
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EVMBackend exports evmBackend to the external tests driving the erc20 and nft packages.
+type EVMBackend = evmBackend
+
+func NewEVMBackend(t *testing.T) *EVMBackend {
+	return newEVMBackend(t)
+}
+
+func (b *evmBackend) SetCode(address common.Address, code []byte) {
+	b.setCode(address, code)
+}
+
+func (b *evmBackend) SetStorage(address common.Address, slot common.Hash, value common.Hash) {
+	b.setStorage(address, slot, value)
+}
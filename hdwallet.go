@@ -0,0 +1,130 @@
+package multicall
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// HDSigner signs with an account derived from a BIP-39 mnemonic with BIP-32, at the path
+// made of its base path and index (m/44'/60'/0'/0/index by default).
+type HDSigner struct {
+	*GenericSigner
+	Index uint32
+
+	seed     []byte
+	basePath accounts.DerivationPath
+}
+
+// NewHDSigner derives the account at the given index. An empty basePath uses
+// m/44'/60'/0'/0, and passphrase is the optional BIP-39 passphrase.
+func NewHDSigner(mnemonic string, passphrase string, basePath string, index uint32) (*HDSigner, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic: %w", err)
+	}
+
+	path := accounts.DefaultRootDerivationPath
+	if basePath != "" {
+		path, err = accounts.ParseDerivationPath(basePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	wallet := &HDSigner{seed: seed, basePath: path}
+
+	return wallet.Account(index)
+}
+
+// Account returns the signer of another index of the same wallet, e.g. to send a batch
+// from a given account with MultiCall.Signer.
+func (s *HDSigner) Account(index uint32) (*HDSigner, error) {
+	path := s.Path(index)
+	privateKey, err := deriveKey(s.seed, path)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving %s: %w", path, err)
+	}
+
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	return &HDSigner{
+		GenericSigner: &GenericSigner{PrivateKey: privateKey, Address: &address},
+		Index:         index,
+		seed:          s.seed,
+		basePath:      s.basePath,
+	}, nil
+}
+
+// Addresses returns the addresses of the count accounts starting at index from.
+func (s *HDSigner) Addresses(from uint32, count uint32) ([]common.Address, error) {
+	addresses := make([]common.Address, count)
+	for i := range addresses {
+		account, err := s.Account(from + uint32(i))
+		if err != nil {
+			return nil, err
+		}
+		addresses[i] = *account.GetAddress()
+	}
+
+	return addresses, nil
+}
+
+// Path returns the derivation path of the given index.
+func (s *HDSigner) Path(index uint32) accounts.DerivationPath {
+	path := make(accounts.DerivationPath, len(s.basePath), len(s.basePath)+1)
+	copy(path, s.basePath)
+
+	return append(path, index)
+}
+
+// deriveKey derives the private key at path from the seed, following BIP-32.
+func deriveKey(seed []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := new(big.Int).SetBytes(sum[:32]), sum[32:]
+
+	n := crypto.S256().Params().N
+	if key.Sign() == 0 || key.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("invalid master key")
+	}
+
+	for _, index := range path {
+		var data []byte
+		if index >= 0x80000000 {
+			data = append([]byte{0}, common.LeftPadBytes(key.Bytes(), 32)...)
+		} else {
+			privateKey, err := crypto.ToECDSA(common.LeftPadBytes(key.Bytes(), 32))
+			if err != nil {
+				return nil, err
+			}
+			data = crypto.CompressPubkey(&privateKey.PublicKey)
+		}
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+
+		tweak := new(big.Int).SetBytes(sum[:32])
+		if tweak.Cmp(n) >= 0 {
+			return nil, fmt.Errorf("invalid child key at index %d", index)
+		}
+		key = tweak.Add(tweak, key).Mod(tweak, n)
+		if key.Sign() == 0 {
+			return nil, fmt.Errorf("invalid child key at index %d", index)
+		}
+		chainCode = sum[32:]
+	}
+
+	return crypto.ToECDSA(common.LeftPadBytes(key.Bytes(), 32))
+}
@@ -0,0 +1,60 @@
+package multicall
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const testMnemonic = "test test test test test test test test test test test junk"
+
+func TestHDSigner(t *testing.T) {
+	signer, err := NewHDSigner(testMnemonic, "", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"); *signer.GetAddress() != expected {
+		t.Errorf("expected address %s, got %s", expected, signer.GetAddress())
+	}
+	if path := signer.Path(3).String(); path != "m/44'/60'/0'/0/3" {
+		t.Errorf("unexpected path %s", path)
+	}
+
+	addresses, err := signer.Addresses(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []common.Address{
+		common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"),
+		common.HexToAddress("0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC"),
+	}
+	if !reflect.DeepEqual(addresses, expected) {
+		t.Errorf("expected addresses %v, got %v", expected, addresses)
+	}
+
+	account, err := signer.Account(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainId := big.NewInt(1337)
+	to := common.HexToAddress("0x1")
+	signedTx, err := account.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID: chainId, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10), Gas: 21000, To: &to,
+	}), chainId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sender, err := types.Sender(types.LatestSignerForChainID(chainId), signedTx); err != nil || sender != expected[1] {
+		t.Errorf("expected sender %s, got %s (%v)", expected[1], sender, err)
+	}
+
+	if _, err := NewHDSigner("test test test", "", "", 0); err == nil {
+		t.Errorf("expected an error for an invalid mnemonic")
+	}
+	if _, err := NewHDSigner(testMnemonic, "", "m/44'/60'/x", 0); err == nil {
+		t.Errorf("expected an error for an invalid path")
+	}
+}
@@ -0,0 +1,154 @@
+package multicall
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Capabilities are the standards detected for an address by DetectInterfaces.
+type Capabilities struct {
+	Address    common.Address
+	IsContract bool
+	ERC165     bool
+	ERC20      bool
+	ERC721     bool
+	ERC1155    bool
+	ERC4626    bool
+	ERC1271    bool
+}
+
+// interface probes of DetectInterfaces, in the order of the calls of each address
+const (
+	probeERC165 = iota
+	probeInvalidInterface
+	probeERC721
+	probeERC1155
+	probeTotalSupply
+	probeBalanceOf
+	probeAllowance
+	probeAsset
+	probeTotalAssets
+	probeIsValidSignature
+	probesCount
+)
+
+// DetectInterfaces classifies the addresses as EOAs or contracts and detects the
+// standards the contracts implement. ERC-721 and ERC-1155 are detected with ERC-165,
+// ERC-20, ERC-4626 and ERC-1271 with heuristic probes of their methods, as most of their
+// implementations do not support ERC-165. Result.Result is a []Capabilities.
+func (m *MultiCall) DetectInterfaces(
+	addresses []*common.Address, client Backend, blockNumber *big.Int,
+) Result {
+	return m.DetectInterfacesContext(context.Background(), addresses, client, blockNumber)
+}
+
+// DetectInterfacesContext is like DetectInterfaces but runs with the given context.
+func (m *MultiCall) DetectInterfacesContext(
+	ctx context.Context, addresses []*common.Address, client Backend, blockNumber *big.Int,
+) Result {
+	if blockNumber == nil {
+		blockNumberUint64, err := client.BlockNumber(ctx)
+		if err != nil {
+			return Result{Success: false, Error: fmt.Errorf("error getting block number: %w", err)}
+		}
+		blockNumber = new(big.Int).SetUint64(blockNumberUint64)
+	}
+
+	codeLengths := m.CodeLengthsContext(ctx, addresses, client, blockNumber)
+	if !codeLengths.Success {
+		return codeLengths
+	}
+
+	calls := make([]TypedCall[bool], 0, len(addresses)*probesCount)
+	for _, address := range addresses {
+		calls = append(calls, interfaceProbes(*address)...)
+	}
+
+	outcomes, txOrCall, err := TryAggregateContext(ctx, m, calls, client, blockNumber)
+	if err != nil {
+		return Result{Success: false, Error: err, TxOrCall: txOrCall}
+	}
+
+	return Result{
+		Success:  true,
+		Result:   newCapabilities(addresses, codeLengths.Result.([]*big.Int), outcomes),
+		TxOrCall: txOrCall,
+	}
+}
+
+// interfaceProbes returns the probes of an address, each decoding to whether it passed.
+func interfaceProbes(address common.Address) []TypedCall[bool] {
+	supportsInterface := func(interfaceID [4]byte) TypedCall[bool] {
+		return NewTypedCall(address, "supportsInterface(bytes4)", []any{interfaceID[:]}, DecodeAs[bool]("bool"))
+	}
+	returnsWord := func(funcSignature string, args ...any) TypedCall[bool] {
+		return NewTypedCall(address, funcSignature, args, decodesWord)
+	}
+
+	probes := make([]TypedCall[bool], probesCount)
+	probes[probeERC165] = supportsInterface(ERC165_INTERFACE_ID)
+	probes[probeInvalidInterface] = supportsInterface(INVALID_INTERFACE_ID)
+	probes[probeERC721] = supportsInterface(ERC721_INTERFACE_ID)
+	probes[probeERC1155] = supportsInterface(ERC1155_INTERFACE_ID)
+	probes[probeTotalSupply] = returnsWord("totalSupply()")
+	probes[probeBalanceOf] = returnsWord("balanceOf(address)", &ZERO_ADDRESS)
+	probes[probeAllowance] = returnsWord("allowance(address,address)", &ZERO_ADDRESS, &ZERO_ADDRESS)
+	probes[probeAsset] = NewTypedCall(address, "asset()", nil, decodesAddress)
+	probes[probeTotalAssets] = returnsWord("totalAssets()")
+	probes[probeIsValidSignature] = NewTypedCall(
+		address, "isValidSignature(bytes32,bytes)", []any{make([]byte, 32), []byte{}}, decodesBytes4,
+	)
+
+	return probes
+}
+
+func newCapabilities(addresses []*common.Address, codeLengths []*big.Int, outcomes []Outcome[bool]) []Capabilities {
+	capabilities := make([]Capabilities, len(addresses))
+	for i, address := range addresses {
+		probes := outcomes[i*probesCount : (i+1)*probesCount]
+		passed := func(probe int) bool {
+			return probes[probe].Success && probes[probe].Value
+		}
+
+		c := Capabilities{Address: *address, IsContract: codeLengths[i].Sign() > 0}
+		if c.IsContract {
+			c.ERC165 = passed(probeERC165) && probes[probeInvalidInterface].Success && !probes[probeInvalidInterface].Value
+			c.ERC721 = c.ERC165 && passed(probeERC721)
+			c.ERC1155 = c.ERC165 && passed(probeERC1155)
+			c.ERC20 = !c.ERC721 && !c.ERC1155 &&
+				passed(probeTotalSupply) && passed(probeBalanceOf) && passed(probeAllowance)
+			c.ERC4626 = c.ERC20 && passed(probeAsset) && passed(probeTotalAssets)
+			c.ERC1271 = passed(probeIsValidSignature)
+		}
+		capabilities[i] = c
+	}
+
+	return capabilities
+}
+
+// decodesWord checks the return data is a single 32 bytes word, as returned by functions
+// returning a uint256.
+func decodesWord(returnData []byte) (bool, error) {
+	return len(returnData) == 32, nil
+}
+
+// decodesAddress checks the return data is an ABI encoded non-zero address.
+func decodesAddress(returnData []byte) (bool, error) {
+	_, ok := decodeAddressWord(returnData)
+
+	return ok, nil
+}
+
+// decodesBytes4 checks the return data is an ABI encoded bytes4, either ERC1271_MAGIC_VALUE
+// or the 0xffffffff returned by most implementations for invalid signatures.
+func decodesBytes4(returnData []byte) (bool, error) {
+	if len(returnData) != 32 || !bytes.Equal(returnData[4:], make([]byte, 28)) {
+		return false, nil
+	}
+
+	return bytes.Equal(returnData[:4], ERC1271_MAGIC_VALUE[:]) || bytes.Equal(returnData[:4], []byte{0xff, 0xff, 0xff, 0xff}), nil
+}
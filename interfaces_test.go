@@ -0,0 +1,54 @@
+package multicall
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// oneCode returns the uint256 1 to any call.
+var oneCode = common.FromHex("0x600160005260206000f3")
+
+func TestDetectInterfaces(t *testing.T) {
+	backend := newEVMBackend(t)
+	eoa, echo, one := common.HexToAddress("0x3333"), common.HexToAddress("0x4444"), common.HexToAddress("0x5555")
+	backend.setCode(echo, echoCode)
+	backend.setCode(one, oneCode)
+	mcall := &MultiCall{MultiCallType: DEPLOYLESS}
+
+	result := mcall.DetectInterfaces([]*common.Address{&eoa, &echo, &one}, backend, nil)
+	if !result.Success {
+		t.Fatal(result.Error)
+	}
+
+	expected := []Capabilities{
+		{Address: eoa},
+		{Address: echo, IsContract: true},
+		// answers true to the invalid interface, so it is not ERC-165
+		{Address: one, IsContract: true, ERC20: true, ERC4626: true},
+	}
+	if !reflect.DeepEqual(result.Result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result.Result)
+	}
+}
+
+func TestNewCapabilities(t *testing.T) {
+	nft, wallet := common.HexToAddress("0x1"), common.HexToAddress("0x2")
+
+	outcomes := make([]Outcome[bool], 2*probesCount)
+	for _, probe := range []int{probeERC165, probeInvalidInterface, probeERC721, probeTotalSupply, probeBalanceOf, probeAllowance} {
+		outcomes[probe] = Outcome[bool]{Success: true, Value: probe != probeInvalidInterface}
+	}
+	outcomes[probesCount+probeIsValidSignature] = Outcome[bool]{Success: true, Value: true}
+
+	capabilities := newCapabilities([]*common.Address{&nft, &wallet}, []*big.Int{big.NewInt(1), big.NewInt(1)}, outcomes)
+	expected := []Capabilities{
+		{Address: nft, IsContract: true, ERC165: true, ERC721: true},
+		{Address: wallet, IsContract: true, ERC1271: true},
+	}
+	if !reflect.DeepEqual(capabilities, expected) {
+		t.Errorf("expected %+v, got %+v", expected, capabilities)
+	}
+}
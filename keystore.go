@@ -0,0 +1,156 @@
+package multicall
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// PassphraseProvider returns the passphrase decrypting the key of the given account.
+type PassphraseProvider func(account accounts.Account) (string, error)
+
+// StaticPassphrase provides the same passphrase for every account.
+func StaticPassphrase(passphrase string) PassphraseProvider {
+	return func(accounts.Account) (string, error) {
+		return passphrase, nil
+	}
+}
+
+// PassphraseFromFile provides the content of the given file, without its trailing newline,
+// for every account.
+func PassphraseFromFile(path string) PassphraseProvider {
+	return func(accounts.Account) (string, error) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("error reading passphrase file: %w", err)
+		}
+
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+}
+
+// KeystoreSigner signs with an account of a go-ethereum keystore directory. The key is
+// decrypted for every signature and never kept in memory.
+type KeystoreSigner struct {
+	KeyStore   *keystore.KeyStore
+	Account    accounts.Account
+	Passphrase PassphraseProvider
+}
+
+// NewKeystoreSigner opens the keystore directory and selects the account with the given
+// address, or its only account when address is nil.
+func NewKeystoreSigner(keyDir string, address *common.Address, passphrase PassphraseProvider) (SignerInterface, error) {
+	if _, err := os.Stat(keyDir); err != nil {
+		return nil, fmt.Errorf("error opening keystore: %w", err)
+	}
+
+	keyStore := keystore.NewKeyStore(keyDir, keystore.StandardScryptN, keystore.StandardScryptP)
+
+	var account accounts.Account
+	if address != nil {
+		found, err := keyStore.Find(accounts.Account{Address: *address})
+		if err != nil {
+			return nil, fmt.Errorf("error finding account %s in keystore: %w", address, err)
+		}
+		account = found
+	} else {
+		keyStoreAccounts := keyStore.Accounts()
+		if len(keyStoreAccounts) != 1 {
+			return nil, fmt.Errorf("keystore has %d accounts, an address must be given", len(keyStoreAccounts))
+		}
+		account = keyStoreAccounts[0]
+	}
+
+	return &KeystoreSigner{KeyStore: keyStore, Account: account, Passphrase: passphrase}, nil
+}
+
+// NewKeystoreFileSigner decrypts a single keystore file into a GenericSigner.
+func NewKeystoreFileSigner(path string, passphrase PassphraseProvider) (SignerInterface, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading keystore file: %w", err)
+	}
+
+	var account accounts.Account
+	if address, err := keystoreAddress(keyJSON); err == nil {
+		account.Address = address
+	}
+	account.URL = accounts.URL{Scheme: keystore.KeyStoreScheme, Path: path}
+
+	pass, err := passphrase(account)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := keystore.DecryptKey(keyJSON, pass)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting keystore file: %w", err)
+	}
+
+	return &GenericSigner{PrivateKey: key.PrivateKey, Address: &key.Address}, nil
+}
+
+func (s *KeystoreSigner) SignTx(tx *types.Transaction, chainId *big.Int) (*types.Transaction, error) {
+	passphrase, err := s.Passphrase(s.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.KeyStore.SignTxWithPassphrase(s.Account, passphrase, tx, chainId)
+}
+
+func (s *KeystoreSigner) SignMessage(message []byte) ([]byte, error) {
+	return s.signHash(accounts.TextHash(message))
+}
+
+func (s *KeystoreSigner) SignTypedData(typedData apitypes.TypedData) ([]byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("error hashing typed data: %w", err)
+	}
+
+	return s.signHash(hash)
+}
+
+func (s *KeystoreSigner) signHash(hash []byte) ([]byte, error) {
+	passphrase, err := s.Passphrase(s.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := s.KeyStore.SignHashWithPassphrase(s.Account, passphrase, hash)
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+
+	return signature, nil
+}
+
+func (s *KeystoreSigner) GetAddress() *common.Address {
+	return &s.Account.Address
+}
+
+// keystoreAddress reads the unencrypted address field of a keystore file.
+func keystoreAddress(keyJSON []byte) (common.Address, error) {
+	var key struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(keyJSON, &key); err != nil {
+		return common.Address{}, err
+	}
+	if !common.IsHexAddress(key.Address) {
+		return common.Address{}, fmt.Errorf("invalid keystore address %q", key.Address)
+	}
+
+	return common.HexToAddress(key.Address), nil
+}
@@ -0,0 +1,80 @@
+package multicall
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestKeystoreSigners(t *testing.T) {
+	keyDir := t.TempDir()
+	keyStore := keystore.NewKeyStore(keyDir, keystore.LightScryptN, keystore.LightScryptP)
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	account, err := keyStore.ImportECDSA(privateKey, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chainId := big.NewInt(1337)
+	to := common.HexToAddress("0x1")
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID: chainId, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10), Gas: 21000, To: &to,
+	})
+
+	dirSigner, err := NewKeystoreSigner(keyDir, nil, StaticPassphrase("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileSigner, err := NewKeystoreFileSigner(account.URL.Path, StaticPassphrase("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, signer := range []SignerInterface{dirSigner, fileSigner} {
+		if *signer.GetAddress() != account.Address {
+			t.Errorf("expected address %s, got %s", account.Address, signer.GetAddress())
+		}
+
+		signedTx, err := signer.SignTx(tx, chainId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sender, err := types.Sender(types.LatestSignerForChainID(chainId), signedTx)
+		if err != nil || sender != account.Address {
+			t.Errorf("expected sender %s, got %s (%v)", account.Address, sender, err)
+		}
+	}
+
+	message := []byte("hello")
+	signature, err := dirSigner.(MessageSigner).SignMessage(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recovered, err := RecoverSigner(accounts.TextHash(message), signature); err != nil || recovered != account.Address {
+		t.Errorf("expected message signed by %s, got %s (%v)", account.Address, recovered, err)
+	}
+
+	wrongSigner, err := NewKeystoreSigner(keyDir, &account.Address, StaticPassphrase("wrong"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wrongSigner.SignTx(tx, chainId); err == nil {
+		t.Errorf("expected an error with a wrong passphrase")
+	}
+	if _, err := NewKeystoreFileSigner(account.URL.Path, StaticPassphrase("wrong")); err == nil {
+		t.Errorf("expected an error with a wrong passphrase")
+	}
+
+	missing := common.HexToAddress("0x2")
+	if _, err := NewKeystoreSigner(keyDir, &missing, StaticPassphrase("secret")); err == nil {
+		t.Errorf("expected an error for a missing account")
+	}
+}
@@ -7,7 +7,7 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 type MultiCall struct {
@@ -15,9 +15,19 @@ type MultiCall struct {
 	WriteAddress  *common.Address
 	ReadAddress   *common.Address
 	Signer        *SignerInterface
+
+	// Overrides are sent with every read, see WithOverrides.
+	Overrides *CallOverrides
+}
+
+func NewMultiCall(multiCallType MultiCallType, client Backend, signer *SignerInterface) (*MultiCall, error) {
+	return NewMultiCallContext(context.Background(), multiCallType, client, signer)
 }
 
-func NewMultiCall(multiCallType MultiCallType, client *ethclient.Client, signer *SignerInterface) (*MultiCall, error) {
+// NewMultiCallContext is like NewMultiCall but probes the multicall bytecode with the given context.
+func NewMultiCallContext(
+	ctx context.Context, multiCallType MultiCallType, client Backend, signer *SignerInterface,
+) (*MultiCall, error) {
 	if multiCallType > 1 {
 		return nil, fmt.Errorf("invalid multi call type %d", multiCallType)
 
@@ -39,7 +49,7 @@ func NewMultiCall(multiCallType MultiCallType, client *ethclient.Client, signer
 	}
 
 	if writeAddress.Cmp(*readAddress) == 0 {
-		bytecode, err := client.CodeAt(context.Background(), *writeAddress, nil)
+		bytecode, err := client.CodeAt(ctx, *writeAddress, nil)
 		if err != nil {
 			return nil, fmt.Errorf("error getting bytecode: %v", err)
 		}
@@ -47,12 +57,14 @@ func NewMultiCall(multiCallType MultiCallType, client *ethclient.Client, signer
 		if len(bytecode) == 0 {
 			log.Printf("no deployed contract found. Using deployless method\n\n")
 
-			writeAddress = nil
-			multiCallType = DEPLOYLESS
+			return &MultiCall{
+				MultiCallType: DEPLOYLESS,
+				Signer:        signer,
+			}, nil
 		}
 
 		toDeployless := writeAddress.Cmp(OMNES_MULTICALL_ADDRESS) == 0
-		contractDeployed, newAddress, err := isContract(client, writeAddress, toDeployless, false)
+		contractDeployed, newAddress, err := isContract(ctx, client, writeAddress, toDeployless, false)
 		if err != nil {
 			return nil, fmt.Errorf("error checking contract: %v", err)
 		}
@@ -74,14 +86,14 @@ func NewMultiCall(multiCallType MultiCallType, client *ethclient.Client, signer
 		}, nil
 	} else {
 		toDeployless := writeAddress.Cmp(OMNES_MULTICALL_ADDRESS) == 0
-		contractDeployed, newAddress, err := isContract(client, writeAddress, toDeployless, false)
+		contractDeployed, newAddress, err := isContract(ctx, client, writeAddress, toDeployless, false)
 		if err != nil {
 			return nil, fmt.Errorf("error checking contract: %v", err)
 		}
 
 		if !contractDeployed {
 			writeAddress = newAddress
-			if toDeployless {
+			if newAddress == nil {
 				multiCallType = DEPLOYLESS
 			} else {
 				multiCallType = OMNES
@@ -89,7 +101,7 @@ func NewMultiCall(multiCallType MultiCallType, client *ethclient.Client, signer
 		}
 
 		toDeployless = readAddress.Cmp(OMNES_MULTICALL_ADDRESS) == 0
-		contractDeployed, newAddress, err = isContract(client, readAddress, toDeployless, true)
+		contractDeployed, newAddress, err = isContract(ctx, client, readAddress, toDeployless, true)
 		if err != nil {
 			return nil, fmt.Errorf("error checking contract: %v", err)
 		}
@@ -107,8 +119,24 @@ func NewMultiCall(multiCallType MultiCallType, client *ethclient.Client, signer
 	}
 }
 
+// WithOverrides returns a copy of the multicall sending the given state and block
+// overrides with every read, e.g. to simulate balances or code that are not on chain.
+func (m *MultiCall) WithOverrides(overrides *CallOverrides) *MultiCall {
+	mc := *m
+	mc.Overrides = overrides
+
+	return &mc
+}
+
 func (m *MultiCall) AggregateCalls(
-	calls []Call, client *ethclient.Client, blockNumber *big.Int, isCall bool,
+	calls []Call, client Backend, blockNumber *big.Int, isCall bool, opts *TxOptions,
+) Result {
+	return m.AggregateCallsContext(context.Background(), calls, client, blockNumber, isCall, opts)
+}
+
+// AggregateCallsContext is like AggregateCalls but runs with the given context.
+func (m *MultiCall) AggregateCallsContext(
+	ctx context.Context, calls []Call, client Backend, blockNumber *big.Int, isCall bool, opts *TxOptions,
 ) Result {
 	if m.Signer == nil && !isCall {
 		return Result{Success: false, Error: fmt.Errorf("no signer configured")}
@@ -119,6 +147,7 @@ func (m *MultiCall) AggregateCalls(
 			return Result{Success: false, Error: fmt.Errorf("cannot do call with multi call type %d", m.MultiCallType)}
 		} else {
 			return transact(
+				ctx,
 				calls,
 				false,
 				client,
@@ -128,11 +157,13 @@ func (m *MultiCall) AggregateCalls(
 				[]string{"bytes[]"},
 				false,
 				false,
+				opts,
 			)
 		}
 	} else if m.MultiCallType == OMNES {
 		if isCall {
 			return txAsRead(
+				ctx,
 				calls,
 				false,
 				client,
@@ -141,9 +172,11 @@ func (m *MultiCall) AggregateCalls(
 				[]string{"bytes[]"},
 				&m.MultiCallType,
 				blockNumber,
+				m.Overrides,
 			)
 		} else {
 			return transact(
+				ctx,
 				calls,
 				false,
 				client,
@@ -153,6 +186,7 @@ func (m *MultiCall) AggregateCalls(
 				[]string{"bytes[]"},
 				true,
 				false,
+				opts,
 			)
 		}
 	} else {
@@ -161,7 +195,14 @@ func (m *MultiCall) AggregateCalls(
 }
 
 func (m *MultiCall) TryAggregateCalls(
-	calls []Call, requireSuccess bool, client *ethclient.Client, blockNumber *big.Int, isCall bool,
+	calls []Call, requireSuccess bool, client Backend, blockNumber *big.Int, isCall bool, opts *TxOptions,
+) Result {
+	return m.TryAggregateCallsContext(context.Background(), calls, requireSuccess, client, blockNumber, isCall, opts)
+}
+
+// TryAggregateCallsContext is like TryAggregateCalls but runs with the given context.
+func (m *MultiCall) TryAggregateCallsContext(
+	ctx context.Context, calls []Call, requireSuccess bool, client Backend, blockNumber *big.Int, isCall bool, opts *TxOptions,
 ) Result {
 	if m.Signer == nil && !isCall {
 		return Result{Success: false, Error: fmt.Errorf("no signer configured")}
@@ -172,6 +213,7 @@ func (m *MultiCall) TryAggregateCalls(
 	} else if m.MultiCallType == OMNES {
 		if isCall {
 			return txAsRead(
+				ctx,
 				calls,
 				requireSuccess,
 				client,
@@ -180,9 +222,11 @@ func (m *MultiCall) TryAggregateCalls(
 				[]string{"(bool,bytes)[]"},
 				&m.MultiCallType,
 				blockNumber,
+				m.Overrides,
 			)
 		} else {
 			return transact(
+				ctx,
 				calls,
 				requireSuccess,
 				client,
@@ -192,6 +236,7 @@ func (m *MultiCall) TryAggregateCalls(
 				[]string{"(bool,bytes)[]"},
 				true,
 				false,
+				opts,
 			)
 		}
 	} else {
@@ -200,7 +245,14 @@ func (m *MultiCall) TryAggregateCalls(
 }
 
 func (m *MultiCall) TryAggregateCalls3(
-	calls []CallWithFailure, client *ethclient.Client, blockNumber *big.Int, isCall bool,
+	calls []CallWithFailure, client Backend, blockNumber *big.Int, isCall bool, opts *TxOptions,
+) Result {
+	return m.TryAggregateCalls3Context(context.Background(), calls, client, blockNumber, isCall, opts)
+}
+
+// TryAggregateCalls3Context is like TryAggregateCalls3 but runs with the given context.
+func (m *MultiCall) TryAggregateCalls3Context(
+	ctx context.Context, calls []CallWithFailure, client Backend, blockNumber *big.Int, isCall bool, opts *TxOptions,
 ) Result {
 	if m.Signer == nil && !isCall {
 		return Result{Success: false, Error: fmt.Errorf("no signer configured")}
@@ -213,6 +265,7 @@ func (m *MultiCall) TryAggregateCalls3(
 			return Result{Success: false, Error: fmt.Errorf("cannot do call with multi call type %d", m.MultiCallType)}
 		} else {
 			return transactWithFailure(
+				ctx,
 				calls,
 				false,
 				client,
@@ -222,11 +275,13 @@ func (m *MultiCall) TryAggregateCalls3(
 				[]string{"(bool,bytes)[]"},
 				withValue,
 				true,
+				opts,
 			)
 		}
 	} else if m.MultiCallType == OMNES {
 		if isCall {
 			return txAsReadWithFailure(
+				ctx,
 				calls,
 				false,
 				client,
@@ -235,9 +290,11 @@ func (m *MultiCall) TryAggregateCalls3(
 				[]string{"(bool,bytes)[]"},
 				&m.MultiCallType,
 				blockNumber,
+				m.Overrides,
 			)
 		} else {
 			return transactWithFailure(
+				ctx,
 				calls,
 				false,
 				client,
@@ -247,6 +304,7 @@ func (m *MultiCall) TryAggregateCalls3(
 				[]string{"(bool,bytes)[]"},
 				true,
 				false,
+				opts,
 			)
 		}
 	} else {
@@ -254,14 +312,132 @@ func (m *MultiCall) TryAggregateCalls3(
 	}
 }
 
+func (m *MultiCall) BuildAggregateCalls(calls []Call, client Backend, opts *TxOptions) (*types.Transaction, error) {
+	return m.BuildAggregateCallsContext(context.Background(), calls, client, opts)
+}
+
+// BuildAggregateCallsContext returns the unsigned transaction AggregateCalls would send.
+func (m *MultiCall) BuildAggregateCallsContext(
+	ctx context.Context, calls []Call, client Backend, opts *TxOptions,
+) (*types.Transaction, error) {
+	if m.MultiCallType == GENERAL {
+		return m.build(ctx, Calls(calls), false, client, "aggregate((address,bytes)[])", false, false, opts)
+	} else if m.MultiCallType == OMNES {
+		return m.build(ctx, Calls(calls), false, client, "aggregateCalls((address,bytes,uint256)[])", true, false, opts)
+	} else {
+		return nil, fmt.Errorf("cannot build transaction with multi call type %d", m.MultiCallType)
+	}
+}
+
+func (m *MultiCall) BuildTryAggregateCalls(
+	calls []Call, requireSuccess bool, client Backend, opts *TxOptions,
+) (*types.Transaction, error) {
+	return m.BuildTryAggregateCallsContext(context.Background(), calls, requireSuccess, client, opts)
+}
+
+// BuildTryAggregateCallsContext returns the unsigned transaction TryAggregateCalls would send.
+func (m *MultiCall) BuildTryAggregateCallsContext(
+	ctx context.Context, calls []Call, requireSuccess bool, client Backend, opts *TxOptions,
+) (*types.Transaction, error) {
+	if m.MultiCallType == OMNES {
+		return m.build(
+			ctx, Calls(calls), requireSuccess, client, "tryAggregateCalls((address,bytes,uint256)[],bool)", true, false, opts,
+		)
+	} else {
+		return nil, fmt.Errorf("cannot build transaction with multi call type %d", m.MultiCallType)
+	}
+}
+
+func (m *MultiCall) BuildTryAggregateCalls3(
+	calls []CallWithFailure, client Backend, opts *TxOptions,
+) (*types.Transaction, error) {
+	return m.BuildTryAggregateCalls3Context(context.Background(), calls, client, opts)
+}
+
+// BuildTryAggregateCalls3Context returns the unsigned transaction TryAggregateCalls3 would send.
+func (m *MultiCall) BuildTryAggregateCalls3Context(
+	ctx context.Context, calls []CallWithFailure, client Backend, opts *TxOptions,
+) (*types.Transaction, error) {
+	if m.MultiCallType == GENERAL {
+		withValue, funcSignature := isWithValue(calls)
+		return m.build(ctx, CallsWithFailure(calls), false, client, funcSignature, withValue, true, opts)
+	} else if m.MultiCallType == OMNES {
+		return m.build(
+			ctx, CallsWithFailure(calls), false, client, "tryAggregateCalls((address,bytes,uint256,bool)[])", true, false, opts,
+		)
+	} else {
+		return nil, fmt.Errorf("cannot build transaction with multi call type %d", m.MultiCallType)
+	}
+}
+
+func (m *MultiCall) SignTransaction(tx *types.Transaction, client Backend) ([]byte, error) {
+	return m.SignTransactionContext(context.Background(), tx, client)
+}
+
+// SignTransactionContext signs the transaction with the configured signer and returns
+// its binary (RLP) encoding, ready to be handed to Broadcast.
+func (m *MultiCall) SignTransactionContext(ctx context.Context, tx *types.Transaction, client Backend) ([]byte, error) {
+	if m.Signer == nil {
+		return nil, fmt.Errorf("no signer configured")
+	}
+
+	chainId, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting chain id: %w", err)
+	}
+
+	signedTx, err := (*m.Signer).SignTx(tx, chainId)
+	if err != nil {
+		return nil, fmt.Errorf("error signing transaction: %w", err)
+	}
+
+	return signedTx.MarshalBinary()
+}
+
+func (m *MultiCall) Broadcast(rawTx []byte, client Backend) Result {
+	return m.BroadcastContext(context.Background(), rawTx, client)
+}
+
+// BroadcastContext sends a signed transaction and waits for its receipt.
+func (m *MultiCall) BroadcastContext(ctx context.Context, rawTx []byte, client Backend) Result {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(rawTx); err != nil {
+		return Result{Success: false, Error: fmt.Errorf("error decoding transaction: %w", err)}
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return Result{Success: false, Error: fmt.Errorf("error recovering sender: %w", err)}
+	}
+
+	receipt, err := sendSignedTransaction(ctx, client, tx)
+	if err != nil {
+		return Result{Success: false, Error: err, TxOrCall: FromTxToTxOrCall(tx, from, nil)}
+	}
+
+	return Result{
+		Success:  receipt.Status == types.ReceiptStatusSuccessful,
+		Result:   receipt,
+		TxOrCall: FromTxToTxOrCall(tx, from, receipt.BlockNumber),
+	}
+}
+
 func (m *MultiCall) SimulateCall(
-	calls []Call, client *ethclient.Client, blockNumber *big.Int,
+	calls []Call, client Backend, blockNumber *big.Int,
+) Result {
+	return m.SimulateCallContext(context.Background(), calls, client, blockNumber)
+}
+
+// SimulateCallContext is like SimulateCall but runs with the given context.
+func (m *MultiCall) SimulateCallContext(
+	ctx context.Context, calls []Call, client Backend, blockNumber *big.Int,
 ) Result {
 
 	if m.MultiCallType == GENERAL {
-		return deploylessSimulation(calls, client, blockNumber)
+		return deploylessSimulation(ctx, calls, client, blockNumber, m.Overrides)
 	} else if m.MultiCallType == OMNES {
 		return call(
+			ctx,
 			calls,
 			false,
 			client,
@@ -269,23 +445,59 @@ func (m *MultiCall) SimulateCall(
 			"simulateCalls((address,bytes)[])",
 			nil,
 			&m.MultiCallType,
-			m.WriteAddress,
 			blockNumber,
 			true,
+			m.Overrides,
 		)
 	} else {
-		return deploylessSimulation(calls, client, blockNumber)
+		return deploylessSimulation(ctx, calls, client, blockNumber, m.Overrides)
 	}
 }
 
+// SimulateDelegateCalls previews a batch executed with DELEGATECALL, as a smart account
+// would, and returns the success, return data and gas used of each call. With a nil account
+// the calls run in a fresh deployless contract, otherwise in the context of account, whose
+// code is replaced for the simulation with a state override. Only DEPLOYLESS multicalls
+// support it, and as DELEGATECALL forwards no value, calls with a value are rejected.
+func (m *MultiCall) SimulateDelegateCalls(
+	calls []Call, account *common.Address, client Backend, blockNumber *big.Int,
+) Result {
+	return m.SimulateDelegateCallsContext(context.Background(), calls, account, client, blockNumber)
+}
+
+// SimulateDelegateCallsContext is like SimulateDelegateCalls but runs with the given context.
+func (m *MultiCall) SimulateDelegateCallsContext(
+	ctx context.Context, calls []Call, account *common.Address, client Backend, blockNumber *big.Int,
+) Result {
+	if m.MultiCallType != DEPLOYLESS {
+		return Result{Success: false, Error: fmt.Errorf("cannot simulate delegate calls with multi call type %d", m.MultiCallType)}
+	}
+	for i, call := range calls {
+		if call.Value != nil && call.Value.Sign() != 0 {
+			return Result{Success: false, Error: fmt.Errorf("cannot simulate delegate call %d with value %s", i, call.Value)}
+		}
+	}
+
+	// the deployless bytecode has no delegate call mode, the calls run in a generated simulator
+	return deploylessDelegateSimulation(ctx, calls, account, client, blockNumber, m.Overrides)
+}
+
 func (m *MultiCall) AggregateStatic(
-	calls []Call, client *ethclient.Client, blockNumber *big.Int,
+	calls []Call, client Backend, blockNumber *big.Int,
+) Result {
+	return m.AggregateStaticContext(context.Background(), calls, client, blockNumber)
+}
+
+// AggregateStaticContext is like AggregateStatic but runs with the given context.
+func (m *MultiCall) AggregateStaticContext(
+	ctx context.Context, calls []Call, client Backend, blockNumber *big.Int,
 ) Result {
 
 	if m.MultiCallType == GENERAL {
-		return deploylessAggregateStatic(calls, client, blockNumber)
+		return deploylessAggregateStatic(ctx, calls, client, blockNumber, m.Overrides)
 	} else if m.MultiCallType == OMNES {
 		return call(
+			ctx,
 			calls,
 			false,
 			client,
@@ -293,23 +505,31 @@ func (m *MultiCall) AggregateStatic(
 			"aggregateStatic((address,bytes)[])",
 			[]string{"bytes[]"},
 			&m.MultiCallType,
-			m.WriteAddress,
 			blockNumber,
 			false,
+			m.Overrides,
 		)
 	} else {
-		return deploylessAggregateStatic(calls, client, blockNumber)
+		return deploylessAggregateStatic(ctx, calls, client, blockNumber, m.Overrides)
 	}
 }
 
 func (m *MultiCall) TryAggregateStatic(
-	calls []Call, requireSuccess bool, client *ethclient.Client, blockNumber *big.Int,
+	calls []Call, requireSuccess bool, client Backend, blockNumber *big.Int,
+) Result {
+	return m.TryAggregateStaticContext(context.Background(), calls, requireSuccess, client, blockNumber)
+}
+
+// TryAggregateStaticContext is like TryAggregateStatic but runs with the given context.
+func (m *MultiCall) TryAggregateStaticContext(
+	ctx context.Context, calls []Call, requireSuccess bool, client Backend, blockNumber *big.Int,
 ) Result {
 
 	if m.MultiCallType == GENERAL {
-		return deploylessTryAggregateStatic(calls, requireSuccess, client, blockNumber)
+		return deploylessTryAggregateStatic(ctx, calls, requireSuccess, client, blockNumber, m.Overrides)
 	} else if m.MultiCallType == OMNES {
 		return call(
+			ctx,
 			calls,
 			requireSuccess,
 			client,
@@ -317,123 +537,184 @@ func (m *MultiCall) TryAggregateStatic(
 			"tryAggregateStatic((address,bytes)[],bool)",
 			[]string{"(bool,bytes)[]"},
 			&m.MultiCallType,
-			m.WriteAddress,
 			blockNumber,
 			false,
+			m.Overrides,
 		)
 	} else {
-		return deploylessTryAggregateStatic(calls, requireSuccess, client, blockNumber)
+		return deploylessTryAggregateStatic(ctx, calls, requireSuccess, client, blockNumber, m.Overrides)
 	}
 }
 
 func (m *MultiCall) TryAggregateStatic3(
-	calls []CallWithFailure, client *ethclient.Client, blockNumber *big.Int,
+	calls []CallWithFailure, client Backend, blockNumber *big.Int,
+) Result {
+	return m.TryAggregateStatic3Context(context.Background(), calls, client, blockNumber)
+}
+
+// TryAggregateStatic3Context is like TryAggregateStatic3 but runs with the given context.
+func (m *MultiCall) TryAggregateStatic3Context(
+	ctx context.Context, calls []CallWithFailure, client Backend, blockNumber *big.Int,
 ) Result {
 
 	if m.MultiCallType == GENERAL {
-		return deploylessTryAggregateStatic3(calls, client, blockNumber)
+		return deploylessTryAggregateStatic3(ctx, calls, client, blockNumber, m.Overrides)
 	} else if m.MultiCallType == OMNES {
 		return callWithFailure(
+			ctx,
 			calls,
 			client,
 			m.WriteAddress,
 			"tryAggregateStatic((address,bytes,bool)[])",
 			[]string{"(bool,bytes)[]"},
 			&m.MultiCallType,
-			m.WriteAddress,
 			blockNumber,
+			m.Overrides,
 		)
 	} else {
-		return deploylessTryAggregateStatic3(calls, client, blockNumber)
+		return deploylessTryAggregateStatic3(ctx, calls, client, blockNumber, m.Overrides)
 	}
 }
 
 func (m *MultiCall) CodeLengths(
-	addresses []*common.Address, client *ethclient.Client, blockNumber *big.Int,
+	addresses []*common.Address, client Backend, blockNumber *big.Int,
+) Result {
+	return m.CodeLengthsContext(context.Background(), addresses, client, blockNumber)
+}
+
+// CodeLengthsContext is like CodeLengths but runs with the given context.
+func (m *MultiCall) CodeLengthsContext(
+	ctx context.Context, addresses []*common.Address, client Backend, blockNumber *big.Int,
 ) Result {
 
 	if m.MultiCallType == GENERAL {
-		return deploylessGetCodeLengths(addresses, client, blockNumber)
+		return deploylessGetCodeLengths(ctx, addresses, client, blockNumber, m.Overrides)
 	} else if m.MultiCallType == OMNES {
-		return getData(
+		return bigIntsResult(getData(
+			ctx,
 			addresses,
 			client,
 			m.ReadAddress,
 			"getCodeLengths(address[])",
 			[]string{"uint256[]"},
 			blockNumber,
-		)
+			m.Overrides,
+		), addresses)
 	} else {
-		return deploylessGetCodeLengths(addresses, client, blockNumber)
+		return deploylessGetCodeLengths(ctx, addresses, client, blockNumber, m.Overrides)
 	}
 }
 
 func (m *MultiCall) Balances(
-	addresses []*common.Address, client *ethclient.Client, blockNumber *big.Int,
+	addresses []*common.Address, client Backend, blockNumber *big.Int,
+) Result {
+	return m.BalancesContext(context.Background(), addresses, client, blockNumber)
+}
+
+// BalancesContext is like Balances but runs with the given context.
+func (m *MultiCall) BalancesContext(
+	ctx context.Context, addresses []*common.Address, client Backend, blockNumber *big.Int,
 ) Result {
 
 	if m.MultiCallType == GENERAL {
-		return deploylessGetBalances(addresses, client, blockNumber)
+		return deploylessGetBalances(ctx, addresses, client, blockNumber, m.Overrides)
 	} else if m.MultiCallType == OMNES {
-		return getData(
+		return bigIntsResult(getData(
+			ctx,
 			addresses,
 			client,
 			m.ReadAddress,
 			"getBalances(address[])",
 			[]string{"uint256[]"},
 			blockNumber,
-		)
+			m.Overrides,
+		), addresses)
 	} else {
-		return deploylessGetBalances(addresses, client, blockNumber)
+		return deploylessGetBalances(ctx, addresses, client, blockNumber, m.Overrides)
 	}
 }
 
 func (m *MultiCall) AddressesData(
-	addresses []*common.Address, client *ethclient.Client, blockNumber *big.Int,
+	addresses []*common.Address, client Backend, blockNumber *big.Int,
+) Result {
+	return m.AddressesDataContext(context.Background(), addresses, client, blockNumber)
+}
+
+// AddressesDataContext is like AddressesData but runs with the given context.
+func (m *MultiCall) AddressesDataContext(
+	ctx context.Context, addresses []*common.Address, client Backend, blockNumber *big.Int,
 ) Result {
 
 	if m.MultiCallType == GENERAL {
-		return deploylessGetAddressesData(addresses, client, blockNumber)
+		return deploylessGetAddressesData(ctx, addresses, client, blockNumber, m.Overrides)
 	} else if m.MultiCallType == OMNES {
-		return getData(
+		return addressesInfoResult(getData(
+			ctx,
 			addresses,
 			client,
 			m.ReadAddress,
 			"getAddressesData(address[])",
 			[]string{"uint256[]", "uint256[]"},
 			blockNumber,
-		)
+			m.Overrides,
+		), addresses)
 	} else {
-		return deploylessGetAddressesData(addresses, client, blockNumber)
+		return deploylessGetAddressesData(ctx, addresses, client, blockNumber, m.Overrides)
 	}
 }
 
-func (m *MultiCall) ChainData(client *ethclient.Client, blockNumber *big.Int) Result {
+// ChainData returns a ChainInfo with the chain id and the data of the block.
+func (m *MultiCall) ChainData(client Backend, blockNumber *big.Int) Result {
+	return m.ChainDataContext(context.Background(), client, blockNumber)
+}
+
+// ChainDataContext is like ChainData but runs with the given context.
+func (m *MultiCall) ChainDataContext(ctx context.Context, client Backend, blockNumber *big.Int) Result {
 
 	if m.MultiCallType == GENERAL {
-		return deploylessGetChainData(client, blockNumber)
+		return deploylessGetChainData(ctx, client, blockNumber, m.Overrides)
 	} else if m.MultiCallType == OMNES {
-		return getData(
+		return chainInfoResult(getData(
+			ctx,
 			nil,
 			client,
 			m.ReadAddress,
 			"getChainData()",
-			[]string{
-				"uint256",
-				"uint256",
-				"bytes32",
-				"uint256",
-				"address",
-				"uint256",
-				"uint256",
-				"uint256",
-				"uint256",
-			},
+			CHAIN_DATA_RETURN_TYPES,
 			blockNumber,
-		)
+			m.Overrides,
+		))
+	} else {
+		return deploylessGetChainData(ctx, client, blockNumber, m.Overrides)
+	}
+}
+
+func (m *MultiCall) build(
+	ctx context.Context, calls CallsInterface, requireSuccess bool, client Backend,
+	funcSignature string, withValue bool, isMultiCall3Type bool, opts *TxOptions,
+) (*types.Transaction, error) {
+	var from *common.Address
+	if opts != nil && opts.From != nil {
+		from = opts.From
+	} else if m.Signer != nil {
+		from = (*m.Signer).GetAddress()
 	} else {
-		return deploylessGetChainData(client, blockNumber)
+		return nil, fmt.Errorf("no signer or sender configured")
 	}
+
+	// the nonce of a built transaction uses the pending nonce unless given, as nothing
+	// releases a nonce allocated for a transaction that is never broadcast
+	if opts != nil && opts.NonceManager != nil {
+		withoutNonceManager := *opts
+		withoutNonceManager.NonceManager = nil
+		opts = &withoutNonceManager
+	}
+
+	tx, _, err := buildTransaction(
+		ctx, calls, requireSuccess, client, from, m.WriteAddress, funcSignature, withValue, isMultiCall3Type, opts,
+	)
+
+	return tx, err
 }
 
 func isWithValue(calls []CallWithFailure) (bool, string) {
@@ -446,8 +727,8 @@ func isWithValue(calls []CallWithFailure) (bool, string) {
 	return false, "aggregate3((address,bool,bytes)[])"
 }
 
-func isContract(client *ethclient.Client, address *common.Address, toDeployless bool, justForReading bool) (bool, *common.Address, error) {
-	bytecode, err := client.CodeAt(context.Background(), *address, nil)
+func isContract(ctx context.Context, client Backend, address *common.Address, toDeployless bool, justForReading bool) (bool, *common.Address, error) {
+	bytecode, err := client.CodeAt(ctx, *address, nil)
 	if err != nil {
 		return false, nil, fmt.Errorf("error getting bytecode: %v", err)
 	}
@@ -469,7 +750,7 @@ func isContract(client *ethclient.Client, address *common.Address, toDeployless
 			return false, nil, nil
 		}
 
-		return isContract(client, &OMNES_MULTICALL_ADDRESS, true, justForReading)
+		return isContract(ctx, client, &OMNES_MULTICALL_ADDRESS, true, justForReading)
 	}
 
 	return true, address, nil
@@ -11,10 +11,11 @@ import (
 
 func ExampleNewClient() {
 	rpc := "https://eth.llamarpc.com"
-	client, err := ethclient.Dial(rpc)
+	ethClient, err := ethclient.Dial(rpc)
 	if err != nil {
 		panic(err)
 	}
+	client := multicall.NewBackend(ethClient)
 
 	mcall, err := multicall.NewMultiCall(multicall.GENERAL, client, nil)
 	if err != nil {
@@ -29,10 +30,11 @@ func ExampleNewClient() {
 
 func ExampleMultiCall_SimulateCall() {
 	rpc := "https://eth.llamarpc.com"
-	client, err := ethclient.Dial(rpc)
+	ethClient, err := ethclient.Dial(rpc)
 	if err != nil {
 		panic(err)
 	}
+	client := multicall.NewBackend(ethClient)
 
 	mcall, err := multicall.NewMultiCall(multicall.GENERAL, client, nil)
 	if err != nil {
@@ -56,17 +58,25 @@ func ExampleMultiCall_SimulateCall() {
 
 	results := mcall.SimulateCall(calls, client, nil)
 
-	fmt.Println(results)
+	simulation := results.Result.(multicall.Simulation)
+	for _, result := range simulation.Results {
+		fmt.Println(result.Success, result.GasUsed)
+	}
+	fmt.Println(simulation.TotalGasUsed)
 
-	// Output: {true [[true  33921] [true  9521]] <nil>}
+	// Output:
+	// true 33921
+	// true 9521
+	// 43442
 }
 
 func ExampleMultiCall_AggregateStatic() {
 	rpc := "https://eth.llamarpc.com"
-	client, err := ethclient.Dial(rpc)
+	ethClient, err := ethclient.Dial(rpc)
 	if err != nil {
 		panic(err)
 	}
+	client := multicall.NewBackend(ethClient)
 
 	mcall, err := multicall.NewMultiCall(multicall.GENERAL, client, nil)
 	if err != nil {
@@ -103,10 +113,11 @@ func ExampleMultiCall_AggregateStatic() {
 
 func ExampleMultiCall_TryAggregateStatic() {
 	rpc := "https://eth.llamarpc.com"
-	client, err := ethclient.Dial(rpc)
+	ethClient, err := ethclient.Dial(rpc)
 	if err != nil {
 		panic(err)
 	}
+	client := multicall.NewBackend(ethClient)
 
 	mcall, err := multicall.NewMultiCall(multicall.GENERAL, client, nil)
 	if err != nil {
@@ -143,10 +154,11 @@ func ExampleMultiCall_TryAggregateStatic() {
 
 func ExampleMultiCall_TryAggregateStatic3() {
 	rpc := "https://eth.llamarpc.com"
-	client, err := ethclient.Dial(rpc)
+	ethClient, err := ethclient.Dial(rpc)
 	if err != nil {
 		panic(err)
 	}
+	client := multicall.NewBackend(ethClient)
 
 	mcall, err := multicall.NewMultiCall(multicall.GENERAL, client, nil)
 	if err != nil {
@@ -184,10 +196,11 @@ func ExampleMultiCall_TryAggregateStatic3() {
 
 func ExampleMultiCall_CodeLengths() {
 	rpc := "https://eth.llamarpc.com"
-	client, err := ethclient.Dial(rpc)
+	ethClient, err := ethclient.Dial(rpc)
 	if err != nil {
 		panic(err)
 	}
+	client := multicall.NewBackend(ethClient)
 
 	mcall, err := multicall.NewMultiCall(multicall.GENERAL, client, nil)
 	if err != nil {
@@ -210,10 +223,11 @@ func ExampleMultiCall_CodeLengths() {
 
 func ExampleMultiCall_Balances() {
 	rpc := "https://eth.llamarpc.com"
-	client, err := ethclient.Dial(rpc)
+	ethClient, err := ethclient.Dial(rpc)
 	if err != nil {
 		panic(err)
 	}
+	client := multicall.NewBackend(ethClient)
 
 	mcall, err := multicall.NewMultiCall(multicall.GENERAL, client, nil)
 	if err != nil {
@@ -234,10 +248,11 @@ func ExampleMultiCall_Balances() {
 
 func ExampleMultiCall_AddressesData() {
 	rpc := "https://eth.llamarpc.com"
-	client, err := ethclient.Dial(rpc)
+	ethClient, err := ethclient.Dial(rpc)
 	if err != nil {
 		panic(err)
 	}
+	client := multicall.NewBackend(ethClient)
 
 	mcall, err := multicall.NewMultiCall(multicall.GENERAL, client, nil)
 	if err != nil {
@@ -258,10 +273,11 @@ func ExampleMultiCall_AddressesData() {
 
 func ExampleMultiCall_ChainData() {
 	rpc := "https://eth.llamarpc.com"
-	client, err := ethclient.Dial(rpc)
+	ethClient, err := ethclient.Dial(rpc)
 	if err != nil {
 		panic(err)
 	}
+	client := multicall.NewBackend(ethClient)
 
 	mcall, err := multicall.NewMultiCall(multicall.GENERAL, client, nil)
 	if err != nil {
@@ -0,0 +1,346 @@
+// Package nft reads ERC-721 and ERC-1155 ownership and metadata of many tokens with
+// chunked multicalls, reporting the calls that failed for each token.
+package nft
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/omnes-tech/multicall"
+)
+
+// BALANCE_OF_BATCH_SIZE is the maximum number of ids of a balanceOfBatch call.
+const BALANCE_OF_BATCH_SIZE = 200
+
+// NFT is a token of a collection.
+type NFT struct {
+	Collection common.Address
+	ID         *big.Int
+}
+
+// Key identifies a token in the maps returned by this package.
+type Key struct {
+	Collection common.Address
+	ID         string // decimal token id
+}
+
+func (n NFT) Key() Key {
+	return Key{Collection: n.Collection, ID: n.ID.String()}
+}
+
+// Holding is an ERC-1155 token whose balance is read for Owner.
+type Holding struct {
+	Owner common.Address
+	NFT   NFT
+}
+
+// Approval is an owner and operator of an isApprovedForAll query.
+type Approval struct {
+	Owner    common.Address
+	Operator common.Address
+}
+
+// OwnersOf reads ownerOf of ERC-721 tokens. Burned or non-existent tokens have a failed
+// Outcome.
+func OwnersOf(
+	m *multicall.MultiCall, tokens []NFT, client multicall.Backend, blockNumber *big.Int, opts multicall.ChunkOptions,
+) (map[Key]multicall.Outcome[common.Address], multicall.TxOrCall, error) {
+	return OwnersOfContext(context.Background(), m, tokens, client, blockNumber, opts)
+}
+
+// OwnersOfContext is like OwnersOf but runs with the given context.
+func OwnersOfContext(
+	ctx context.Context, m *multicall.MultiCall, tokens []NFT, client multicall.Backend, blockNumber *big.Int,
+	opts multicall.ChunkOptions,
+) (map[Key]multicall.Outcome[common.Address], multicall.TxOrCall, error) {
+	return readTokens(ctx, m, tokens, "ownerOf(uint256)", multicall.DecodeAs[common.Address]("address"), client, blockNumber, opts)
+}
+
+// TokenURIs reads tokenURI of ERC-721 tokens.
+func TokenURIs(
+	m *multicall.MultiCall, tokens []NFT, client multicall.Backend, blockNumber *big.Int, opts multicall.ChunkOptions,
+) (map[Key]multicall.Outcome[string], multicall.TxOrCall, error) {
+	return TokenURIsContext(context.Background(), m, tokens, client, blockNumber, opts)
+}
+
+// TokenURIsContext is like TokenURIs but runs with the given context.
+func TokenURIsContext(
+	ctx context.Context, m *multicall.MultiCall, tokens []NFT, client multicall.Backend, blockNumber *big.Int,
+	opts multicall.ChunkOptions,
+) (map[Key]multicall.Outcome[string], multicall.TxOrCall, error) {
+	return readTokens(ctx, m, tokens, "tokenURI(uint256)", multicall.DecodeAs[string]("string"), client, blockNumber, opts)
+}
+
+// URIs reads uri of ERC-1155 tokens, with the {id} placeholder replaced by the token id.
+func URIs(
+	m *multicall.MultiCall, tokens []NFT, client multicall.Backend, blockNumber *big.Int, opts multicall.ChunkOptions,
+) (map[Key]multicall.Outcome[string], multicall.TxOrCall, error) {
+	return URIsContext(context.Background(), m, tokens, client, blockNumber, opts)
+}
+
+// URIsContext is like URIs but runs with the given context.
+func URIsContext(
+	ctx context.Context, m *multicall.MultiCall, tokens []NFT, client multicall.Backend, blockNumber *big.Int,
+	opts multicall.ChunkOptions,
+) (map[Key]multicall.Outcome[string], multicall.TxOrCall, error) {
+	uris, txOrCall, err := readTokens(ctx, m, tokens, "uri(uint256)", multicall.DecodeAs[string]("string"), client, blockNumber, opts)
+	if err != nil {
+		return nil, txOrCall, err
+	}
+
+	for _, token := range tokens {
+		key := token.Key()
+		if outcome := uris[key]; outcome.Success {
+			outcome.Value = ExpandURI(outcome.Value, token.ID)
+			uris[key] = outcome
+		}
+	}
+
+	return uris, txOrCall, nil
+}
+
+// ExpandURI replaces the ERC-1155 {id} placeholder by the lowercase hex id padded to 64
+// characters.
+func ExpandURI(uri string, id *big.Int) string {
+	return strings.ReplaceAll(uri, "{id}", fmt.Sprintf("%064x", id))
+}
+
+// BalancesOf reads the ERC-721 balanceOf of every owner for every collection, indexed by
+// collection then owner.
+func BalancesOf(
+	m *multicall.MultiCall, collections []common.Address, owners []common.Address,
+	client multicall.Backend, blockNumber *big.Int, opts multicall.ChunkOptions,
+) (map[common.Address]map[common.Address]multicall.Outcome[*big.Int], multicall.TxOrCall, error) {
+	return BalancesOfContext(context.Background(), m, collections, owners, client, blockNumber, opts)
+}
+
+// BalancesOfContext is like BalancesOf but runs with the given context.
+func BalancesOfContext(
+	ctx context.Context, m *multicall.MultiCall, collections []common.Address, owners []common.Address,
+	client multicall.Backend, blockNumber *big.Int, opts multicall.ChunkOptions,
+) (map[common.Address]map[common.Address]multicall.Outcome[*big.Int], multicall.TxOrCall, error) {
+	calls := make([]multicall.TypedCall[*big.Int], 0, len(collections)*len(owners))
+	for _, collection := range collections {
+		for i := range owners {
+			calls = append(calls, multicall.NewTypedCall(
+				collection, "balanceOf(address)", []any{&owners[i]}, multicall.DecodeAs[*big.Int]("uint256"),
+			))
+		}
+	}
+
+	outcomes, txOrCall, err := multicall.TryAggregateChunkedContext(ctx, m, calls, client, blockNumber, opts)
+	if err != nil {
+		return nil, txOrCall, err
+	}
+
+	balances := make(map[common.Address]map[common.Address]multicall.Outcome[*big.Int], len(collections))
+	for i, collection := range collections {
+		if balances[collection] == nil {
+			balances[collection] = make(map[common.Address]multicall.Outcome[*big.Int], len(owners))
+		}
+		for j, owner := range owners {
+			balances[collection][owner] = outcomes[i*len(owners)+j]
+		}
+	}
+
+	return balances, txOrCall, nil
+}
+
+// BalancesOfBatch reads the balances of ERC-1155 holdings with balanceOfBatch, grouping
+// the holdings of each collection by BALANCE_OF_BATCH_SIZE. The balances are indexed by
+// token then owner, and a failed balanceOfBatch fails every holding of its group.
+func BalancesOfBatch(
+	m *multicall.MultiCall, holdings []Holding, client multicall.Backend, blockNumber *big.Int, opts multicall.ChunkOptions,
+) (map[Key]map[common.Address]multicall.Outcome[*big.Int], multicall.TxOrCall, error) {
+	return BalancesOfBatchContext(context.Background(), m, holdings, client, blockNumber, opts)
+}
+
+// BalancesOfBatchContext is like BalancesOfBatch but runs with the given context.
+func BalancesOfBatchContext(
+	ctx context.Context, m *multicall.MultiCall, holdings []Holding, client multicall.Backend, blockNumber *big.Int,
+	opts multicall.ChunkOptions,
+) (map[Key]map[common.Address]multicall.Outcome[*big.Int], multicall.TxOrCall, error) {
+	groups := groupHoldings(holdings)
+
+	calls := make([]multicall.TypedCall[[]any], len(groups))
+	for i, group := range groups {
+		accounts := make([]any, len(group))
+		ids := make([]any, len(group))
+		for j := range group {
+			accounts[j] = &group[j].Owner
+			ids[j] = group[j].NFT.ID
+		}
+		calls[i] = multicall.NewTypedCall(
+			group[0].NFT.Collection, "balanceOfBatch(address[],uint256[])", []any{accounts, ids},
+			multicall.DecodeAs[[]any]("uint256[]"),
+		)
+	}
+
+	outcomes, txOrCall, err := multicall.TryAggregateChunkedContext(ctx, m, calls, client, blockNumber, opts)
+	if err != nil {
+		return nil, txOrCall, err
+	}
+
+	return newHoldingBalances(groups, outcomes), txOrCall, nil
+}
+
+// ApprovalsForAll reads isApprovedForAll of every approval for every collection, indexed
+// by collection then approval.
+func ApprovalsForAll(
+	m *multicall.MultiCall, collections []common.Address, approvals []Approval,
+	client multicall.Backend, blockNumber *big.Int, opts multicall.ChunkOptions,
+) (map[common.Address]map[Approval]multicall.Outcome[bool], multicall.TxOrCall, error) {
+	return ApprovalsForAllContext(context.Background(), m, collections, approvals, client, blockNumber, opts)
+}
+
+// ApprovalsForAllContext is like ApprovalsForAll but runs with the given context.
+func ApprovalsForAllContext(
+	ctx context.Context, m *multicall.MultiCall, collections []common.Address, approvals []Approval,
+	client multicall.Backend, blockNumber *big.Int, opts multicall.ChunkOptions,
+) (map[common.Address]map[Approval]multicall.Outcome[bool], multicall.TxOrCall, error) {
+	calls := make([]multicall.TypedCall[bool], 0, len(collections)*len(approvals))
+	for _, collection := range collections {
+		for i := range approvals {
+			calls = append(calls, multicall.NewTypedCall(
+				collection, "isApprovedForAll(address,address)", []any{&approvals[i].Owner, &approvals[i].Operator},
+				multicall.DecodeAs[bool]("bool"),
+			))
+		}
+	}
+
+	outcomes, txOrCall, err := multicall.TryAggregateChunkedContext(ctx, m, calls, client, blockNumber, opts)
+	if err != nil {
+		return nil, txOrCall, err
+	}
+
+	result := make(map[common.Address]map[Approval]multicall.Outcome[bool], len(collections))
+	for i, collection := range collections {
+		if result[collection] == nil {
+			result[collection] = make(map[Approval]multicall.Outcome[bool], len(approvals))
+		}
+		for j, approval := range approvals {
+			result[collection][approval] = outcomes[i*len(approvals)+j]
+		}
+	}
+
+	return result, txOrCall, nil
+}
+
+// SupportsInterfaces probes ERC-165 supportsInterface of every interface id (e.g.
+// multicall.ERC721_INTERFACE_ID) for every collection, indexed by collection then interface id.
+func SupportsInterfaces(
+	m *multicall.MultiCall, collections []common.Address, interfaceIDs [][4]byte,
+	client multicall.Backend, blockNumber *big.Int, opts multicall.ChunkOptions,
+) (map[common.Address]map[[4]byte]multicall.Outcome[bool], multicall.TxOrCall, error) {
+	return SupportsInterfacesContext(context.Background(), m, collections, interfaceIDs, client, blockNumber, opts)
+}
+
+// SupportsInterfacesContext is like SupportsInterfaces but runs with the given context.
+func SupportsInterfacesContext(
+	ctx context.Context, m *multicall.MultiCall, collections []common.Address, interfaceIDs [][4]byte,
+	client multicall.Backend, blockNumber *big.Int, opts multicall.ChunkOptions,
+) (map[common.Address]map[[4]byte]multicall.Outcome[bool], multicall.TxOrCall, error) {
+	calls := make([]multicall.TypedCall[bool], 0, len(collections)*len(interfaceIDs))
+	for _, collection := range collections {
+		for _, interfaceID := range interfaceIDs {
+			calls = append(calls, multicall.NewTypedCall(
+				collection, "supportsInterface(bytes4)", []any{interfaceID[:]}, multicall.DecodeAs[bool]("bool"),
+			))
+		}
+	}
+
+	outcomes, txOrCall, err := multicall.TryAggregateChunkedContext(ctx, m, calls, client, blockNumber, opts)
+	if err != nil {
+		return nil, txOrCall, err
+	}
+
+	result := make(map[common.Address]map[[4]byte]multicall.Outcome[bool], len(collections))
+	for i, collection := range collections {
+		if result[collection] == nil {
+			result[collection] = make(map[[4]byte]multicall.Outcome[bool], len(interfaceIDs))
+		}
+		for j, interfaceID := range interfaceIDs {
+			result[collection][interfaceID] = outcomes[i*len(interfaceIDs)+j]
+		}
+	}
+
+	return result, txOrCall, nil
+}
+
+// readTokens calls funcSignature with the id of every token.
+func readTokens[T any](
+	ctx context.Context, m *multicall.MultiCall, tokens []NFT, funcSignature string, decoder multicall.Decoder[T],
+	client multicall.Backend, blockNumber *big.Int, opts multicall.ChunkOptions,
+) (map[Key]multicall.Outcome[T], multicall.TxOrCall, error) {
+	calls := make([]multicall.TypedCall[T], len(tokens))
+	for i, token := range tokens {
+		calls[i] = multicall.NewTypedCall(token.Collection, funcSignature, []any{token.ID}, decoder)
+	}
+
+	outcomes, txOrCall, err := multicall.TryAggregateChunkedContext(ctx, m, calls, client, blockNumber, opts)
+	if err != nil {
+		return nil, txOrCall, err
+	}
+
+	result := make(map[Key]multicall.Outcome[T], len(tokens))
+	for i, token := range tokens {
+		result[token.Key()] = outcomes[i]
+	}
+
+	return result, txOrCall, nil
+}
+
+// groupHoldings groups the holdings by collection, in order of first appearance, with
+// at most BALANCE_OF_BATCH_SIZE holdings per group.
+func groupHoldings(holdings []Holding) [][]Holding {
+	var groups [][]Holding
+	open := make(map[common.Address]int)
+	for _, holding := range holdings {
+		i, ok := open[holding.NFT.Collection]
+		if !ok || len(groups[i]) == BALANCE_OF_BATCH_SIZE {
+			i = len(groups)
+			open[holding.NFT.Collection] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], holding)
+	}
+
+	return groups
+}
+
+func newHoldingBalances(
+	groups [][]Holding, outcomes []multicall.Outcome[[]any],
+) map[Key]map[common.Address]multicall.Outcome[*big.Int] {
+	balances := make(map[Key]map[common.Address]multicall.Outcome[*big.Int])
+	for i, group := range groups {
+		outcome := outcomes[i]
+		if outcome.Success && len(outcome.Value) != len(group) {
+			outcome = multicall.Outcome[[]any]{
+				Err: fmt.Errorf("expected %d balances from %s, got %d", len(group), group[0].NFT.Collection, len(outcome.Value)),
+			}
+		}
+
+		for j, holding := range group {
+			key := holding.NFT.Key()
+			if balances[key] == nil {
+				balances[key] = make(map[common.Address]multicall.Outcome[*big.Int])
+			}
+
+			balance := multicall.Outcome[*big.Int]{Err: outcome.Err}
+			if outcome.Success {
+				value, ok := outcome.Value[j].(*big.Int)
+				if ok {
+					balance = multicall.Outcome[*big.Int]{Success: true, Value: value}
+				} else {
+					balance.Err = fmt.Errorf("unexpected balance %T", outcome.Value[j])
+				}
+			}
+			balances[key][holding.Owner] = balance
+		}
+	}
+
+	return balances
+}
@@ -0,0 +1,70 @@
+package nft
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/omnes-tech/multicall"
+)
+
+func TestExpandURI(t *testing.T) {
+	uri := ExpandURI("https://token-cdn-domain/{id}.json", big.NewInt(314592))
+	expected := "https://token-cdn-domain/000000000000000000000000000000000000000000000000000000000004cce0.json"
+	if uri != expected {
+		t.Errorf("expected %s, got %s", expected, uri)
+	}
+}
+
+func TestGroupHoldings(t *testing.T) {
+	first, second := common.HexToAddress("0x1"), common.HexToAddress("0x2")
+
+	var holdings []Holding
+	for i := 0; i < BALANCE_OF_BATCH_SIZE+1; i++ {
+		holdings = append(holdings, Holding{NFT: NFT{Collection: first, ID: big.NewInt(int64(i))}})
+		if i == 0 {
+			holdings = append(holdings, Holding{NFT: NFT{Collection: second, ID: big.NewInt(0)}})
+		}
+	}
+
+	groups := groupHoldings(holdings)
+	sizes := make([]int, len(groups))
+	for i, group := range groups {
+		sizes[i] = len(group)
+	}
+	if !reflect.DeepEqual(sizes, []int{BALANCE_OF_BATCH_SIZE, 1, 1}) {
+		t.Fatalf("unexpected group sizes %v", sizes)
+	}
+	if groups[1][0].NFT.Collection != second || groups[2][0].NFT.ID.Int64() != BALANCE_OF_BATCH_SIZE {
+		t.Errorf("unexpected groups order")
+	}
+}
+
+func TestNewHoldingBalances(t *testing.T) {
+	collection, other := common.HexToAddress("0x1"), common.HexToAddress("0x2")
+	owner, operator := common.HexToAddress("0xa"), common.HexToAddress("0xb")
+	groups := [][]Holding{
+		{
+			{Owner: owner, NFT: NFT{Collection: collection, ID: big.NewInt(1)}},
+			{Owner: operator, NFT: NFT{Collection: collection, ID: big.NewInt(1)}},
+		},
+		{{Owner: owner, NFT: NFT{Collection: other, ID: big.NewInt(2)}}},
+	}
+	reverted := errors.New("execution reverted")
+	outcomes := []multicall.Outcome[[]any]{
+		{Success: true, Value: []any{big.NewInt(3), big.NewInt(4)}},
+		{Err: reverted},
+	}
+
+	balances := newHoldingBalances(groups, outcomes)
+	key := NFT{Collection: collection, ID: big.NewInt(1)}.Key()
+	if balance := balances[key][operator]; !balance.Success || balance.Value.Int64() != 4 {
+		t.Errorf("unexpected balance %+v", balance)
+	}
+	otherKey := NFT{Collection: other, ID: big.NewInt(2)}.Key()
+	if balance := balances[otherKey][owner]; balance.Success || !errors.Is(balance.Err, reverted) {
+		t.Errorf("expected failed balance, got %+v", balance)
+	}
+}
@@ -0,0 +1,194 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// NonceManager allocates the nonces of the transactions sent by its senders locally, so
+// concurrent writes from the same signer do not all use the pending nonce of the node. It
+// is safe for concurrent use, and is shared by passing it in TxOptions.NonceManager.
+type NonceManager struct {
+	mu      sync.Mutex
+	senders map[common.Address]*senderNonces
+}
+
+type senderNonces struct {
+	mu       sync.Mutex
+	synced   bool
+	next     uint64   // next nonce never allocated
+	released []uint64 // released nonces below next, allocated again first, sorted
+}
+
+func NewNonceManager() *NonceManager {
+	return &NonceManager{senders: make(map[common.Address]*senderNonces)}
+}
+
+func (n *NonceManager) sender(from common.Address) *senderNonces {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	s, ok := n.senders[from]
+	if !ok {
+		s = &senderNonces{}
+		n.senders[from] = s
+	}
+
+	return s
+}
+
+// Next allocates a nonce of from, the lowest released one if any. The first allocation
+// starts from the pending nonce of the node.
+func (n *NonceManager) Next(ctx context.Context, client Backend, from common.Address) (uint64, error) {
+	s := n.sender(from)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.synced {
+		pending, err := client.PendingNonceAt(ctx, from)
+		if err != nil {
+			return 0, fmt.Errorf("error getting pending nonce: %w", err)
+		}
+		s.next, s.synced = pending, true
+	}
+
+	if len(s.released) > 0 {
+		nonce := s.released[0]
+		s.released = s.released[1:]
+		return nonce, nil
+	}
+
+	nonce := s.next
+	s.next++
+
+	return nonce, nil
+}
+
+// Release returns a nonce whose transaction was not sent, so it is allocated again instead
+// of leaving a gap that would block the following transactions.
+func (n *NonceManager) Release(from common.Address, nonce uint64) {
+	s := n.sender(from)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.synced || nonce >= s.next {
+		return
+	}
+
+	if i, found := slices.BinarySearch(s.released, nonce); !found {
+		s.released = slices.Insert(s.released, i, nonce)
+	}
+
+	// released nonces at the end are allocated again from next
+	for len(s.released) > 0 && s.released[len(s.released)-1] == s.next-1 {
+		s.released = s.released[:len(s.released)-1]
+		s.next--
+	}
+}
+
+// Resync moves the next nonce of from up to the pending nonce of the node, e.g. after a
+// "nonce too low" error when transactions were sent from elsewhere. Released nonces the
+// node already has are dropped.
+func (n *NonceManager) Resync(ctx context.Context, client Backend, from common.Address) error {
+	pending, err := client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return fmt.Errorf("error getting pending nonce: %w", err)
+	}
+
+	s := n.sender(from)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.synced || pending > s.next {
+		s.next, s.synced = pending, true
+	}
+
+	i, _ := slices.BinarySearch(s.released, pending)
+	s.released = s.released[i:]
+
+	return nil
+}
+
+// Reset forgets the nonces of from, which start again from the pending nonce of the node at
+// the next allocation.
+func (n *NonceManager) Reset(from common.Address) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	delete(n.senders, from)
+}
+
+// broadcastTransaction sends the signed transaction. When its nonce was allocated by the
+// nonce manager, a transaction rejected with a nonce too low is re-signed with a resynced
+// nonce, and the nonce of a transaction the node rejected is released. After a transport
+// error the transaction may have reached the node, so its nonce is kept.
+func broadcastTransaction(
+	ctx context.Context, client Backend, signer SignerInterface, chainId *big.Int, tx *types.Transaction,
+	nonces *NonceManager,
+) (*types.Transaction, error) {
+	for resyncs := 0; ; resyncs++ {
+		err := client.SendTransaction(ctx, tx)
+		if err == nil {
+			return tx, nil
+		}
+		sendErr := fmt.Errorf("error sending transaction (txHash=%v): %v", tx.Hash(), err)
+		if nonces == nil {
+			return nil, sendErr
+		}
+
+		from := *signer.GetAddress()
+		if !isNonceTooLow(err) {
+			if isRejected(err) {
+				nonces.Release(from, tx.Nonce())
+			}
+			return nil, sendErr
+		}
+		if resyncs == MAX_NONCE_RESYNCS {
+			return nil, sendErr
+		}
+
+		if err := nonces.Resync(ctx, client, from); err != nil {
+			return nil, err
+		}
+		nonce, err := nonces.Next(ctx, client, from)
+		if err != nil {
+			return nil, err
+		}
+
+		unsignedTx, err := copyTransaction(tx, nonce, tx.GasTipCap(), tx.GasFeeCap())
+		if err == nil {
+			tx, err = signer.SignTx(unsignedTx, chainId)
+		}
+		if err != nil {
+			nonces.Release(from, nonce)
+			return nil, fmt.Errorf("error signing transaction with resynced nonce: %w", err)
+		}
+	}
+}
+
+func isNonceTooLow(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
+
+// isRejected reports whether the node answered that it rejected the transaction, with a
+// JSON-RPC error or the message of a transaction pool error, unlike timeouts or connection
+// resets. A transaction already known to the node was not rejected.
+func isRejected(err error) bool {
+	message := strings.ToLower(err.Error())
+	if strings.Contains(message, "already known") {
+		return false
+	}
+
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) || strings.Contains(message, "nonce too low") ||
+		strings.Contains(message, "insufficient funds") || strings.Contains(message, "underpriced")
+}
@@ -0,0 +1,188 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestNonceManager(t *testing.T) {
+	backend := newEVMBackend(t)
+	from := common.HexToAddress("0x1111")
+	backend.nonces[from] = 5
+	ctx := context.Background()
+
+	nonces := NewNonceManager()
+	next := func() uint64 {
+		t.Helper()
+		nonce, err := nonces.Next(ctx, backend, from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return nonce
+	}
+
+	if allocated := []uint64{next(), next(), next()}; !slices.Equal(allocated, []uint64{5, 6, 7}) {
+		t.Fatalf("expected nonces 5, 6 and 7, got %v", allocated)
+	}
+
+	nonces.Release(from, 6)
+	if nonce := next(); nonce != 6 {
+		t.Errorf("expected released nonce 6, got %d", nonce)
+	}
+
+	nonces.Release(from, 6)
+	nonces.Release(from, 7)
+	if allocated := []uint64{next(), next(), next()}; !slices.Equal(allocated, []uint64{6, 7, 8}) {
+		t.Errorf("expected nonces 6, 7 and 8 after releasing the last ones, got %v", allocated)
+	}
+
+	backend.nonces[from] = 12
+	nonces.Release(from, 5)
+	if err := nonces.Resync(ctx, backend, from); err != nil {
+		t.Fatal(err)
+	}
+	if nonce := next(); nonce != 12 {
+		t.Errorf("expected resynced nonce 12, got %d", nonce)
+	}
+
+	backend.nonces[from] = 2
+	nonces.Reset(from)
+	if nonce := next(); nonce != 2 {
+		t.Errorf("expected nonce 2 after reset, got %d", nonce)
+	}
+}
+
+func TestNonceManagerConcurrentWrites(t *testing.T) {
+	backend := newEVMBackend(t)
+	backend.checkNonces = true
+	mcall := newTestWriteMultiCall(t, backend)
+	from := *(*mcall.Signer).GetAddress()
+	backend.nonces[from] = 3
+	opts := &TxOptions{NonceManager: NewNonceManager()}
+
+	const writes = 10
+	var wg sync.WaitGroup
+	results := make([]Result, writes)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = mcall.AggregateCalls(newRawCalls([]byte{0x01}), backend, nil, false, opts)
+		}()
+	}
+	wg.Wait()
+
+	var sentNonces []uint64
+	for _, result := range results {
+		if !result.Success {
+			t.Fatalf("write failed: %v", result.Error)
+		}
+		sentNonces = append(sentNonces, result.TxOrCall.Nonce)
+	}
+	slices.Sort(sentNonces)
+	for i, nonce := range sentNonces {
+		if nonce != uint64(3+i) {
+			t.Fatalf("expected consecutive nonces from 3, got %v", sentNonces)
+		}
+	}
+}
+
+func TestNonceManagerResyncAndRelease(t *testing.T) {
+	backend := newEVMBackend(t)
+	backend.checkNonces = true
+	mcall := newTestWriteMultiCall(t, backend)
+	from := *(*mcall.Signer).GetAddress()
+	opts := &TxOptions{NonceManager: NewNonceManager()}
+
+	result := mcall.AggregateCalls(newRawCalls([]byte{0x01}), backend, nil, false, opts)
+	if !result.Success || result.TxOrCall.Nonce != 0 {
+		t.Fatalf("expected nonce 0, got %d (%v)", result.TxOrCall.Nonce, result.Error)
+	}
+
+	// transactions sent from elsewhere
+	backend.nonces[from] = 4
+	result = mcall.AggregateCalls(newRawCalls([]byte{0x01}), backend, nil, false, opts)
+	if !result.Success || result.TxOrCall.Nonce != 4 {
+		t.Fatalf("expected resynced nonce 4, got %d (%v)", result.TxOrCall.Nonce, result.Error)
+	}
+
+	backend.failSends = 1
+	result = mcall.AggregateCalls(newRawCalls([]byte{0x01}), backend, nil, false, opts)
+	if result.Success {
+		t.Fatalf("expected the send to fail")
+	}
+	result = mcall.AggregateCalls(newRawCalls([]byte{0x01}), backend, nil, false, opts)
+	if !result.Success || result.TxOrCall.Nonce != 5 {
+		t.Fatalf("expected released nonce 5, got %d (%v)", result.TxOrCall.Nonce, result.Error)
+	}
+
+	// the node may have received a transaction answered with a transport error
+	backend.dropSends = 1
+	result = mcall.AggregateCalls(newRawCalls([]byte{0x01}), backend, nil, false, opts)
+	if result.Success {
+		t.Fatalf("expected the send to fail")
+	}
+	result = mcall.AggregateCalls(newRawCalls([]byte{0x01}), backend, nil, false, &TxOptions{
+		NonceManager: opts.NonceManager, NoSend: true,
+	})
+	if !result.Success || result.TxOrCall.Nonce != 7 {
+		t.Fatalf("expected nonce 7 after the dropped nonce 6, got %d (%v)", result.TxOrCall.Nonce, result.Error)
+	}
+	nonce, err := opts.NonceManager.Next(context.Background(), backend, from)
+	if err != nil || nonce != 7 {
+		t.Errorf("expected nonce 7 released after NoSend, got %d (%v)", nonce, err)
+	}
+}
+
+func TestNonceManagerIgnoredByBuild(t *testing.T) {
+	backend := newEVMBackend(t)
+	mcall := newTestWriteMultiCall(t, backend)
+	from := *(*mcall.Signer).GetAddress()
+	backend.nonces[from] = 2
+	opts := &TxOptions{NonceManager: NewNonceManager()}
+
+	for range 2 {
+		tx, err := mcall.BuildAggregateCalls(newRawCalls([]byte{0x01}), backend, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tx.Nonce() != 2 {
+			t.Errorf("expected pending nonce 2, got %d", tx.Nonce())
+		}
+	}
+
+	nonce, err := opts.NonceManager.Next(context.Background(), backend, from)
+	if err != nil || nonce != 2 {
+		t.Errorf("expected no nonce allocated by builds, got %d (%v)", nonce, err)
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	wrappedNonceTooLow := fmt.Errorf("error sending: %w", &rejectionError{message: "nonce too low: next nonce 8, tx nonce 7"})
+	if !isNonceTooLow(wrappedNonceTooLow) || !isNonceTooLow(errors.New("Nonce too low")) {
+		t.Errorf("expected nonce too low errors to be detected")
+	}
+
+	for _, test := range []struct {
+		err      error
+		rejected bool
+	}{
+		{&rejectionError{message: "insufficient funds for gas * price + value"}, true},
+		{&rejectionError{message: "replacement transaction underpriced"}, true},
+		{wrappedNonceTooLow, true},
+		{errors.New("transaction underpriced"), true},
+		{&rejectionError{message: "already known"}, false},
+		{errors.New("connection reset by peer"), false},
+		{context.DeadlineExceeded, false},
+	} {
+		if rejected := isRejected(test.err); rejected != test.rejected {
+			t.Errorf("expected rejected %t for %v, got %t", test.rejected, test.err, rejected)
+		}
+	}
+}
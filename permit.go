@@ -0,0 +1,109 @@
+package multicall
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+const PERMIT_SIGNATURE = "permit(address,address,uint256,uint256,uint8,bytes32,bytes32)"
+
+// Permit is an ERC-2612 permit, by the signer, of value tokens to spender.
+type Permit struct {
+	Token common.Address
+	// Name and Version are those of the EIP-712 domain of the token, usually its name and
+	// "1". An empty version is "1".
+	Name     string
+	Version  string
+	ChainID  *big.Int
+	Spender  common.Address
+	Value    *big.Int
+	Nonce    *big.Int // nonces(owner) of the token
+	Deadline *big.Int
+}
+
+// TypedData returns the EIP-712 typed data of the permit signed by owner. Integers are
+// decimal strings so the typed data keeps its precision once sent to a remote signer.
+func (p Permit) TypedData(owner common.Address) apitypes.TypedData {
+	version := p.Version
+	if version == "" {
+		version = "1"
+	}
+
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Permit": {
+				{Name: "owner", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain: apitypes.TypedDataDomain{
+			Name:              p.Name,
+			Version:           version,
+			ChainId:           (*math.HexOrDecimal256)(p.ChainID),
+			VerifyingContract: p.Token.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"owner":    owner.Hex(),
+			"spender":  p.Spender.Hex(),
+			"value":    p.Value.String(),
+			"nonce":    p.Nonce.String(),
+			"deadline": p.Deadline.String(),
+		},
+	}
+}
+
+// SignPermit signs the permit and returns the permit call of the token.
+func SignPermit(signer MessageSigner, permit Permit) (Call, error) {
+	if permit.ChainID == nil || permit.Value == nil || permit.Nonce == nil || permit.Deadline == nil {
+		return Call{}, fmt.Errorf("permit of %s must have a chain ID, value, nonce and deadline", permit.Token)
+	}
+
+	owner := *signer.GetAddress()
+	signature, err := signer.SignTypedData(permit.TypedData(owner))
+	if err != nil {
+		return Call{}, fmt.Errorf("error signing permit of %s: %w", permit.Token, err)
+	}
+
+	spender := permit.Spender
+	args := []any{
+		&owner,
+		&spender,
+		permit.Value,
+		permit.Deadline,
+		big.NewInt(int64(signature[crypto.RecoveryIDOffset])),
+		signature[:32],
+		signature[32:64],
+	}
+
+	return NewCall(permit.Token, PERMIT_SIGNATURE, args, nil, nil, nil), nil
+}
+
+// WithPermits returns the calls preceded by the signed permits, so the allowances are set
+// before the calls spend them.
+func WithPermits(calls Calls, signer MessageSigner, permits ...Permit) (Calls, error) {
+	result := make(Calls, 0, len(permits)+len(calls))
+	for _, permit := range permits {
+		call, err := SignPermit(signer, permit)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, call)
+	}
+
+	return append(result, calls...), nil
+}
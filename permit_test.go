@@ -0,0 +1,116 @@
+package multicall
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/omnes-tech/abi"
+)
+
+var (
+	_ MessageSigner = &GenericSigner{}
+	_ MessageSigner = &KeystoreSigner{}
+	_ MessageSigner = &HDSigner{}
+	_ MessageSigner = &RemoteSigner{}
+)
+
+func TestSignMessage(t *testing.T) {
+	signer, err := NewHDSigner(testMnemonic, "", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := []byte("hello")
+	signature, err := signer.SignMessage(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := signature[crypto.RecoveryIDOffset]; v != 27 && v != 28 {
+		t.Errorf("expected V 27 or 28, got %d", v)
+	}
+	recovered, err := RecoverSigner(accounts.TextHash(message), signature)
+	if err != nil || recovered != *signer.GetAddress() {
+		t.Errorf("expected signer %s, got %s (%v)", signer.GetAddress(), recovered, err)
+	}
+}
+
+func TestSignPermit(t *testing.T) {
+	signer, err := NewHDSigner(testMnemonic, "", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := *signer.GetAddress()
+
+	permit := Permit{
+		Token:    common.HexToAddress("0x1000"),
+		Name:     "Token",
+		ChainID:  big.NewInt(1),
+		Spender:  common.HexToAddress("0x2000"),
+		Value:    new(big.Int).Lsh(big.NewInt(1), 200),
+		Nonce:    big.NewInt(7),
+		Deadline: big.NewInt(1_900_000_000),
+	}
+
+	domainSeparator := crypto.Keccak256(
+		crypto.Keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)")),
+		crypto.Keccak256([]byte(permit.Name)),
+		crypto.Keccak256([]byte("1")),
+		common.BigToHash(permit.ChainID).Bytes(),
+		common.BytesToHash(permit.Token.Bytes()).Bytes(),
+	)
+	structHash := crypto.Keccak256(
+		crypto.Keccak256([]byte("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)")),
+		common.BytesToHash(owner.Bytes()).Bytes(),
+		common.BytesToHash(permit.Spender.Bytes()).Bytes(),
+		common.BigToHash(permit.Value).Bytes(),
+		common.BigToHash(permit.Nonce).Bytes(),
+		common.BigToHash(permit.Deadline).Bytes(),
+	)
+	digest := crypto.Keccak256([]byte{0x19, 0x01}, domainSeparator, structHash)
+
+	hash, _, err := apitypes.TypedDataAndHash(permit.TypedData(owner))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(hash, digest) {
+		t.Fatalf("expected digest %x, got %x", digest, hash)
+	}
+
+	transfer := NewCall(common.HexToAddress("0x3000"), "pull()", nil, nil, nil, nil)
+	calls, err := WithPermits(Calls{transfer}, signer, permit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 || calls[0].Target != permit.Token || calls[1].Target != transfer.Target {
+		t.Fatalf("expected the permit before the call, got %+v", calls)
+	}
+
+	callData, err := encodeCallData(calls, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(callData[:4], abi.EncodeSignature(PERMIT_SIGNATURE)) || len(callData) != 4+7*32 {
+		t.Fatalf("unexpected permit call data %x", callData)
+	}
+	words := callData[4:]
+	if common.BytesToAddress(words[:32]) != owner || common.BytesToAddress(words[32:64]) != permit.Spender ||
+		new(big.Int).SetBytes(words[64:96]).Cmp(permit.Value) != 0 ||
+		new(big.Int).SetBytes(words[96:128]).Cmp(permit.Deadline) != 0 {
+		t.Errorf("unexpected permit arguments %x", words[:128])
+	}
+
+	signature := append(common.CopyBytes(words[160:224]), words[159])
+	recovered, err := RecoverSigner(digest, signature)
+	if err != nil || recovered != owner {
+		t.Errorf("expected permit signed by %s, got %s (%v)", owner, recovered, err)
+	}
+
+	if _, err := SignPermit(signer, Permit{Token: permit.Token}); err == nil {
+		t.Errorf("expected an error for an incomplete permit")
+	}
+}
@@ -0,0 +1,218 @@
+package multicall
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type ProxyType uint8
+
+const (
+	NOT_PROXY ProxyType = iota
+	ERC1967_PROXY
+	BEACON_PROXY
+	MINIMAL_PROXY
+	LEGACY_PROXY
+)
+
+// ProxyInfo is the proxy type and the addresses a proxy points to, zero when unknown.
+type ProxyInfo struct {
+	Address        common.Address
+	Type           ProxyType
+	Implementation common.Address
+	Admin          common.Address
+	Beacon         common.Address
+}
+
+func (p ProxyInfo) IsProxy() bool {
+	return p.Type != NOT_PROXY
+}
+
+// codeReaderCode returns the code of the address given as call data:
+//
+//	PUSH1 0 CALLDATALOAD DUP1 EXTCODESIZE DUP1 PUSH1 0 PUSH1 0 DUP5 EXTCODECOPY PUSH1 0 RETURN
+var codeReaderCode = common.FromHex("0x600035803b8060006000843c6000f3")
+
+// codeReaderAddress is where the code reader is placed with a state override.
+var codeReaderAddress = common.HexToAddress("0x00000000000000000000000000000000c0de0000")
+
+// EIP-1167 minimal proxy code, around the 20 bytes implementation address.
+var (
+	minimalProxyPrefix = common.FromHex("0x363d3d373d3d3d363d73")
+	minimalProxySuffix = common.FromHex("0x5af43d82803e903d91602b57fd5bf3")
+)
+
+// legacyProxyGetters return the implementation of proxies predating ERC-1967.
+var legacyProxyGetters = []string{"implementation()", "masterCopy()", "comptrollerImplementation()"}
+
+// ProxiesInfo resolves the proxies among the addresses: ERC-1967 proxies from their
+// implementation, admin and beacon slots, beacon proxies from the implementation of
+// their beacon, EIP-1167 minimal proxies from their code and older proxies from their
+// legacy getters. Result.Result is a []ProxyInfo.
+//
+// It takes two eth_calls at the same block, not one: reading the slots replaces the code
+// of the addresses, and the beacons to call are only known once their slot is read, so
+// the code, getters and beacons are read by a second eth_call. When the node rejects
+// state overrides, the slots are read with eth_getStorageAt and the code with eth_getCode
+// instead.
+func (m *MultiCall) ProxiesInfo(addresses []*common.Address, client Backend, blockNumber *big.Int) Result {
+	return m.ProxiesInfoContext(context.Background(), addresses, client, blockNumber)
+}
+
+// ProxiesInfoContext is like ProxiesInfo but runs with the given context.
+func (m *MultiCall) ProxiesInfoContext(
+	ctx context.Context, addresses []*common.Address, client Backend, blockNumber *big.Int,
+) Result {
+	if blockNumber == nil {
+		blockNumberUint64, err := client.BlockNumber(ctx)
+		if err != nil {
+			return Result{Success: false, Error: fmt.Errorf("error getting block number: %w", err)}
+		}
+		blockNumber = new(big.Int).SetUint64(blockNumberUint64)
+	}
+
+	proxySlots := []common.Hash{ERC1967_IMPLEMENTATION_SLOT, ERC1967_ADMIN_SLOT, ERC1967_BEACON_SLOT}
+	slots := make([]StorageSlot, 0, len(addresses)*len(proxySlots))
+	for _, address := range addresses {
+		for _, slot := range proxySlots {
+			slots = append(slots, StorageSlot{Address: *address, Slot: slot})
+		}
+	}
+
+	storage := m.StorageAtContext(ctx, slots, client, blockNumber)
+	if !storage.Success {
+		return storage
+	}
+	values := storage.Result.([]common.Hash)
+
+	infos := make([]ProxyInfo, len(addresses))
+	for i, address := range addresses {
+		infos[i] = ProxyInfo{
+			Address:        *address,
+			Implementation: common.BytesToAddress(values[i*len(proxySlots)].Bytes()),
+			Admin:          common.BytesToAddress(values[i*len(proxySlots)+1].Bytes()),
+			Beacon:         common.BytesToAddress(values[i*len(proxySlots)+2].Bytes()),
+		}
+	}
+
+	codes, outcomes, txOrCall, err := m.readProxyCalls(ctx, infos, client, blockNumber)
+	if err != nil {
+		return Result{Success: false, Error: err, TxOrCall: txOrCall}
+	}
+
+	for i := range infos {
+		calls := outcomes[i*(len(legacyProxyGetters)+1) : (i+1)*(len(legacyProxyGetters)+1)]
+		resolveProxy(&infos[i], codes[i], calls[0], calls[1:])
+	}
+
+	return Result{Success: true, Result: infos, TxOrCall: txOrCall}
+}
+
+// readProxyCalls reads the code and calls the legacy getters of every address, and the
+// implementation of its beacon if any. The code is read by the code reader, placed with
+// a state override, or by eth_getCode when the node rejects the override. Any other error
+// is returned.
+func (m *MultiCall) readProxyCalls(
+	ctx context.Context, infos []ProxyInfo, client Backend, blockNumber *big.Int,
+) ([][]byte, []Outcome[[]byte], TxOrCall, error) {
+	raw := func(returnData []byte) ([]byte, error) { return returnData, nil }
+
+	var calls []TypedCall[[]byte]
+	for _, info := range infos {
+		beacon := NewTypedCall(info.Beacon, "implementation()", nil, raw)
+		if info.Beacon == (common.Address{}) {
+			// keeps the calls aligned, the outcome is ignored
+			beacon = NewTypedCall(codeReaderAddress, "", nil, raw)
+			beacon.Call.CallData = []byte{}
+		}
+		calls = append(calls, beacon)
+		for _, getter := range legacyProxyGetters {
+			calls = append(calls, NewTypedCall(info.Address, getter, nil, raw))
+		}
+	}
+
+	codeCalls := make([]TypedCall[[]byte], len(infos))
+	for i, info := range infos {
+		codeCalls[i] = NewTypedCall(codeReaderAddress, "", nil, raw)
+		codeCalls[i].Call.CallData = common.LeftPadBytes(info.Address.Bytes(), 32)
+	}
+
+	withCodeReader := m.WithOverrides(withCode(m.Overrides, codeReaderAddress, codeReaderCode))
+	outcomes, txOrCall, err := TryAggregateContext(ctx, withCodeReader, append(codeCalls, calls...), client, blockNumber)
+	if err == nil {
+		codes := make([][]byte, len(infos))
+		for i, outcome := range outcomes[:len(infos)] {
+			codes[i] = outcome.Value
+		}
+
+		return codes, outcomes[len(infos):], txOrCall, nil
+	}
+	if ctx.Err() != nil || !isOverridesRejected(err) {
+		return nil, nil, txOrCall, err
+	}
+
+	outcomes, txOrCall, err = TryAggregateContext(ctx, m, calls, client, blockNumber)
+	if err != nil {
+		return nil, nil, txOrCall, err
+	}
+
+	codes := make([][]byte, len(infos))
+	for i, info := range infos {
+		codes[i], err = client.CodeAt(ctx, info.Address, blockNumber)
+		if err != nil {
+			return nil, nil, txOrCall, fmt.Errorf("error getting code of %s: %w", info.Address, err)
+		}
+	}
+
+	return codes, outcomes, txOrCall, nil
+}
+
+// resolveProxy sets the type of the proxy and its implementation, from its ERC-1967
+// slots first, then its beacon, its code and its legacy getters.
+func resolveProxy(info *ProxyInfo, code []byte, beacon Outcome[[]byte], getters []Outcome[[]byte]) {
+	if info.Implementation != (common.Address{}) {
+		info.Type = ERC1967_PROXY
+		return
+	}
+
+	if info.Beacon != (common.Address{}) {
+		info.Type = BEACON_PROXY
+		if implementation, ok := decodeAddressWord(beacon.Value); beacon.Success && ok {
+			info.Implementation = implementation
+		}
+		return
+	}
+
+	if len(code) == len(minimalProxyPrefix)+common.AddressLength+len(minimalProxySuffix) &&
+		bytes.HasPrefix(code, minimalProxyPrefix) && bytes.HasSuffix(code, minimalProxySuffix) {
+		info.Type = MINIMAL_PROXY
+		info.Implementation = common.BytesToAddress(code[len(minimalProxyPrefix) : len(minimalProxyPrefix)+common.AddressLength])
+		return
+	}
+
+	if len(code) == 0 {
+		return
+	}
+	for _, getter := range getters {
+		if implementation, ok := decodeAddressWord(getter.Value); getter.Success && ok {
+			info.Type = LEGACY_PROXY
+			info.Implementation = implementation
+			return
+		}
+	}
+}
+
+// decodeAddressWord decodes an ABI encoded non-zero address.
+func decodeAddressWord(returnData []byte) (common.Address, bool) {
+	if len(returnData) != 32 || !bytes.Equal(returnData[:12], make([]byte, 12)) {
+		return common.Address{}, false
+	}
+
+	address := common.BytesToAddress(returnData)
+
+	return address, address != common.Address{}
+}
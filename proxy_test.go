@@ -0,0 +1,63 @@
+package multicall
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// addressReturningCode returns the given address to any call.
+func addressReturningCode(address common.Address) []byte {
+	code := append([]byte{0x73}, address.Bytes()...)
+
+	return append(code, common.FromHex("0x60005260206000f3")...)
+}
+
+func TestProxiesInfo(t *testing.T) {
+	backend := newEVMBackend(t)
+	implementation, admin := common.HexToAddress("0x1111"), common.HexToAddress("0x2222")
+	erc1967, beaconProxy, beacon := common.HexToAddress("0x3001"), common.HexToAddress("0x3002"), common.HexToAddress("0x3003")
+	minimal, legacy, plain, eoa := common.HexToAddress("0x3004"), common.HexToAddress("0x3005"), common.HexToAddress("0x3006"), common.HexToAddress("0x3007")
+
+	backend.setCode(erc1967, echoCode)
+	backend.setStorage(erc1967, ERC1967_IMPLEMENTATION_SLOT, common.BytesToHash(implementation.Bytes()))
+	backend.setStorage(erc1967, ERC1967_ADMIN_SLOT, common.BytesToHash(admin.Bytes()))
+	backend.setCode(beaconProxy, echoCode)
+	backend.setStorage(beaconProxy, ERC1967_BEACON_SLOT, common.BytesToHash(beacon.Bytes()))
+	backend.setCode(beacon, addressReturningCode(implementation))
+	minimalCode := append(append(append([]byte{}, minimalProxyPrefix...), implementation.Bytes()...), minimalProxySuffix...)
+	backend.setCode(minimal, minimalCode)
+	backend.setCode(legacy, addressReturningCode(implementation))
+	backend.setCode(plain, echoCode)
+
+	expected := []ProxyInfo{
+		{Address: erc1967, Type: ERC1967_PROXY, Implementation: implementation, Admin: admin},
+		{Address: beaconProxy, Type: BEACON_PROXY, Implementation: implementation, Beacon: beacon},
+		{Address: minimal, Type: MINIMAL_PROXY, Implementation: implementation},
+		{Address: legacy, Type: LEGACY_PROXY, Implementation: implementation},
+		{Address: plain},
+		{Address: eoa},
+	}
+	addresses := make([]*common.Address, len(expected))
+	for i := range expected {
+		addresses[i] = &expected[i].Address
+	}
+
+	for _, noOverrides := range []bool{false, true} {
+		backend.noOverrides = noOverrides
+		result := (&MultiCall{MultiCallType: DEPLOYLESS}).ProxiesInfo(addresses, backend, nil)
+		if !result.Success || !reflect.DeepEqual(result.Result, expected) {
+			t.Errorf("unexpected proxies without overrides %t: %+v (%v)", noOverrides, result.Result, result.Error)
+		}
+	}
+
+	backend.noOverrides = false
+	backend.overridesErr = errors.New("request timed out")
+	result := (&MultiCall{MultiCallType: DEPLOYLESS}).ProxiesInfo(addresses, backend, nil)
+	if result.Success || !strings.Contains(result.Error.Error(), "request timed out") {
+		t.Errorf("expected the error of the code reader call, got %+v (%v)", result.Result, result.Error)
+	}
+}
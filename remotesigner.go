@@ -0,0 +1,268 @@
+package multicall
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+type RemoteSignerType uint8
+
+const (
+	CLEF_SIGNER RemoteSignerType = iota // account_list and account_signTransaction
+	ETH_SIGNER                          // eth_accounts and eth_signTransaction
+)
+
+// RemoteSigner delegates the signatures to an external signer over JSON-RPC, e.g. Clef or
+// a node with unlocked accounts, so the key never enters the process.
+type RemoteSigner struct {
+	Client  *rpc.Client
+	Type    RemoteSignerType
+	Address common.Address
+	// Timeout bounds each request to the remote signer, none when zero as Clef may wait
+	// for a manual approval.
+	Timeout time.Duration
+}
+
+// NewRemoteSigner connects to the remote signer at endpoint (HTTP, WebSocket or IPC) and
+// selects the account with the given address, or its only account when address is nil.
+func NewRemoteSigner(endpoint string, signerType RemoteSignerType, address *common.Address) (*RemoteSigner, error) {
+	return NewRemoteSignerContext(context.Background(), endpoint, signerType, address)
+}
+
+// NewRemoteSignerContext is like NewRemoteSigner but runs with the given context.
+func NewRemoteSignerContext(
+	ctx context.Context, endpoint string, signerType RemoteSignerType, address *common.Address,
+) (*RemoteSigner, error) {
+	client, err := rpc.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to remote signer: %w", err)
+	}
+
+	signer, err := NewRemoteSignerWithClient(ctx, client, signerType, address)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return signer, nil
+}
+
+// NewRemoteSignerWithClient is like NewRemoteSignerContext with an existing client.
+func NewRemoteSignerWithClient(
+	ctx context.Context, client *rpc.Client, signerType RemoteSignerType, address *common.Address,
+) (*RemoteSigner, error) {
+	method := "account_list"
+	if signerType == ETH_SIGNER {
+		method = "eth_accounts"
+	}
+
+	var remoteAccounts []common.Address
+	if err := client.CallContext(ctx, &remoteAccounts, method); err != nil {
+		return nil, fmt.Errorf("error listing remote signer accounts: %w", err)
+	}
+
+	signer := &RemoteSigner{Client: client, Type: signerType}
+	if address != nil {
+		if !slices.Contains(remoteAccounts, *address) {
+			return nil, fmt.Errorf("account %s not found in remote signer", address)
+		}
+		signer.Address = *address
+	} else {
+		if len(remoteAccounts) != 1 {
+			return nil, fmt.Errorf("remote signer has %d accounts, an address must be given", len(remoteAccounts))
+		}
+		signer.Address = remoteAccounts[0]
+	}
+
+	return signer, nil
+}
+
+func (s *RemoteSigner) SignTx(tx *types.Transaction, chainId *big.Int) (*types.Transaction, error) {
+	args, err := remoteSignerArgs(s.Address, tx, chainId)
+	if err != nil {
+		return nil, err
+	}
+
+	method := "account_signTransaction"
+	if s.Type == ETH_SIGNER {
+		method = "eth_signTransaction"
+	}
+
+	ctx, cancel := s.context()
+	defer cancel()
+
+	var response json.RawMessage
+	if err := s.Client.CallContext(ctx, &response, method, args); err != nil {
+		return nil, fmt.Errorf("error signing with remote signer: %w", err)
+	}
+
+	signedTx, err := decodeSignedTx(response)
+	if err != nil {
+		return nil, err
+	}
+
+	sender, err := types.Sender(types.LatestSignerForChainID(signedTx.ChainId()), signedTx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote signature: %w", err)
+	}
+	if sender != s.Address {
+		return nil, fmt.Errorf("remote signer signed with %s instead of %s", sender, s.Address)
+	}
+
+	// the signer must not change the nonce, fees or anything else of the transaction
+	requestedChainId := (*big.Int)(args.ChainID)
+	hasher := types.LatestSignerForChainID(requestedChainId)
+	if hasher.Hash(signedTx) != hasher.Hash(tx) ||
+		(requestedChainId != nil && signedTx.ChainId().Cmp(requestedChainId) != 0) {
+		return nil, fmt.Errorf("remote signer signed a different transaction than %s", hasher.Hash(tx))
+	}
+
+	return signedTx, nil
+}
+
+// SignMessage signs with account_signData as text/plain, or eth_sign.
+func (s *RemoteSigner) SignMessage(message []byte) ([]byte, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	var signature hexutil.Bytes
+	var err error
+	if s.Type == ETH_SIGNER {
+		err = s.Client.CallContext(ctx, &signature, "eth_sign", s.Address, hexutil.Bytes(message))
+	} else {
+		err = s.Client.CallContext(
+			ctx, &signature, "account_signData", "text/plain", common.NewMixedcaseAddress(s.Address), hexutil.Bytes(message),
+		)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error signing message with remote signer: %w", err)
+	}
+
+	return s.checkSignature(accounts.TextHash(message), signature)
+}
+
+// SignTypedData signs with account_signTypedData, or eth_signTypedData_v4.
+func (s *RemoteSigner) SignTypedData(typedData apitypes.TypedData) ([]byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("error hashing typed data: %w", err)
+	}
+
+	ctx, cancel := s.context()
+	defer cancel()
+
+	var signature hexutil.Bytes
+	if s.Type == ETH_SIGNER {
+		err = s.Client.CallContext(ctx, &signature, "eth_signTypedData_v4", s.Address, typedData)
+	} else {
+		err = s.Client.CallContext(ctx, &signature, "account_signTypedData", common.NewMixedcaseAddress(s.Address), typedData)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error signing typed data with remote signer: %w", err)
+	}
+
+	return s.checkSignature(hash, signature)
+}
+
+// checkSignature normalizes V to 27 or 28 and checks the signature is from the account.
+func (s *RemoteSigner) checkSignature(hash []byte, signature []byte) ([]byte, error) {
+	signer, err := RecoverSigner(hash, signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote signature: %w", err)
+	}
+	if signer != s.Address {
+		return nil, fmt.Errorf("remote signer signed with %s instead of %s", signer, s.Address)
+	}
+
+	if signature[crypto.RecoveryIDOffset] < 27 {
+		signature[crypto.RecoveryIDOffset] += 27
+	}
+
+	return signature, nil
+}
+
+func (s *RemoteSigner) GetAddress() *common.Address {
+	return &s.Address
+}
+
+func (s *RemoteSigner) context() (context.Context, context.CancelFunc) {
+	if s.Timeout == 0 {
+		return context.WithCancel(context.Background())
+	}
+
+	return context.WithTimeout(context.Background(), s.Timeout)
+}
+
+// remoteSignerArgs returns the transaction arguments of account_signTransaction, also
+// accepted by eth_signTransaction.
+func remoteSignerArgs(from common.Address, tx *types.Transaction, chainId *big.Int) (*apitypes.SendTxArgs, error) {
+	data := hexutil.Bytes(tx.Data())
+	args := &apitypes.SendTxArgs{
+		From:  common.NewMixedcaseAddress(from),
+		Gas:   hexutil.Uint64(tx.Gas()),
+		Value: hexutil.Big(*tx.Value()),
+		Nonce: hexutil.Uint64(tx.Nonce()),
+		Input: &data,
+	}
+	if tx.To() != nil {
+		to := common.NewMixedcaseAddress(*tx.To())
+		args.To = &to
+	}
+
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	case types.DynamicFeeTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	default:
+		return nil, fmt.Errorf("unsupported transaction type %d", tx.Type())
+	}
+
+	if chainId != nil && chainId.Sign() != 0 {
+		args.ChainID = (*hexutil.Big)(chainId)
+	}
+	if tx.Type() != types.LegacyTxType {
+		if tx.ChainId().Sign() != 0 {
+			args.ChainID = (*hexutil.Big)(tx.ChainId())
+		}
+		accessList := tx.AccessList()
+		args.AccessList = &accessList
+	}
+
+	return args, nil
+}
+
+// decodeSignedTx decodes the {raw, tx} object returned by Clef and go-ethereum, or the
+// raw transaction alone returned by some nodes.
+func decodeSignedTx(response json.RawMessage) (*types.Transaction, error) {
+	var raw hexutil.Bytes
+	if err := json.Unmarshal(response, &raw); err != nil {
+		var result struct {
+			Raw hexutil.Bytes `json:"raw"`
+		}
+		if err := json.Unmarshal(response, &result); err != nil {
+			return nil, fmt.Errorf("unexpected remote signer response: %s", response)
+		}
+		raw = result.Raw
+	}
+
+	signedTx := new(types.Transaction)
+	if err := signedTx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("error decoding signed transaction: %w", err)
+	}
+
+	return signedTx, nil
+}
@@ -0,0 +1,194 @@
+package multicall
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// stubSigner serves account_list/account_signTransaction and eth_accounts/eth_signTransaction.
+type stubSigner struct {
+	privateKey *ecdsa.PrivateKey
+	rawOnly    bool
+	tamper     func(args *apitypes.SendTxArgs)
+}
+
+type stubSignerResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+func (s *stubSigner) List() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(s.privateKey.PublicKey)}
+}
+
+func (s *stubSigner) Accounts() []common.Address {
+	return s.List()
+}
+
+func (s *stubSigner) SignTransaction(args apitypes.SendTxArgs) (any, error) {
+	if s.tamper != nil {
+		s.tamper(&args)
+	}
+	tx, err := args.ToTransaction()
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID((*big.Int)(args.ChainID)), s.privateKey)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := signedTx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if s.rawOnly {
+		return hexutil.Bytes(raw), nil
+	}
+
+	return stubSignerResult{Raw: raw, Tx: signedTx}, nil
+}
+
+// SignData serves account_signData, returning V as 27 or 28 like Clef.
+func (s *stubSigner) SignData(contentType string, address common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error) {
+	return signHash(accounts.TextHash(data), s.privateKey)
+}
+
+// Sign serves eth_sign, returning V as 0 or 1 like some nodes.
+func (s *stubSigner) Sign(address common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error) {
+	return crypto.Sign(accounts.TextHash(data), s.privateKey)
+}
+
+func (s *stubSigner) SignTypedData(address common.MixedcaseAddress, typedData apitypes.TypedData) (hexutil.Bytes, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, err
+	}
+
+	return signHash(hash, s.privateKey)
+}
+
+func (s *stubSigner) SignTypedData_v4(address common.MixedcaseAddress, typedData apitypes.TypedData) (hexutil.Bytes, error) {
+	return s.SignTypedData(address, typedData)
+}
+
+func TestRemoteSigner(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	chainId := big.NewInt(1337)
+	to := common.HexToAddress("0x1")
+	txs := []*types.Transaction{
+		types.NewTx(&types.DynamicFeeTx{
+			ChainID: chainId, Nonce: 3, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10), Gas: 50000,
+			To: &to, Value: big.NewInt(5), Data: []byte{0x12, 0x34},
+		}),
+		types.NewTx(&types.LegacyTx{Nonce: 4, GasPrice: big.NewInt(10), Gas: 21000, To: &to}),
+	}
+
+	for _, test := range []struct {
+		name       string
+		signerType RemoteSignerType
+		rawOnly    bool
+	}{
+		{"clef", CLEF_SIGNER, false},
+		{"eth", ETH_SIGNER, false},
+		{"eth raw", ETH_SIGNER, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			stub := &stubSigner{privateKey: privateKey, rawOnly: test.rawOnly}
+			server := rpc.NewServer()
+			defer server.Stop()
+			if err := server.RegisterName("account", stub); err != nil {
+				t.Fatal(err)
+			}
+			if err := server.RegisterName("eth", stub); err != nil {
+				t.Fatal(err)
+			}
+			httpServer := httptest.NewServer(server)
+			defer httpServer.Close()
+
+			signer, err := NewRemoteSigner(httpServer.URL, test.signerType, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *signer.GetAddress() != address {
+				t.Errorf("expected address %s, got %s", address, signer.GetAddress())
+			}
+
+			for _, tx := range txs {
+				signedTx, err := signer.SignTx(tx, chainId)
+				if err != nil {
+					t.Fatal(err)
+				}
+				sender, err := types.Sender(types.LatestSignerForChainID(chainId), signedTx)
+				if err != nil || sender != address {
+					t.Errorf("expected sender %s, got %s (%v)", address, sender, err)
+				}
+				if signedTx.Type() != tx.Type() || signedTx.Nonce() != tx.Nonce() ||
+					signedTx.Value().Cmp(tx.Value()) != 0 || string(signedTx.Data()) != string(tx.Data()) {
+					t.Errorf("signed transaction differs from %+v", tx)
+				}
+			}
+
+			for name, tamper := range map[string]func(args *apitypes.SendTxArgs){
+				"nonce":    func(args *apitypes.SendTxArgs) { args.Nonce++ },
+				"value":    func(args *apitypes.SendTxArgs) { args.Value = hexutil.Big(*big.NewInt(6)) },
+				"chain id": func(args *apitypes.SendTxArgs) { args.ChainID = (*hexutil.Big)(big.NewInt(1)) },
+			} {
+				stub.tamper = tamper
+				for _, tx := range txs {
+					if _, err := signer.SignTx(tx, chainId); err == nil {
+						t.Errorf("expected an error with a different %s signed for %d", name, tx.Type())
+					}
+				}
+			}
+			stub.tamper = nil
+
+			message := []byte("hello")
+			signature, err := signer.SignMessage(message)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v := signature[crypto.RecoveryIDOffset]; v != 27 && v != 28 {
+				t.Errorf("expected V 27 or 28, got %d", v)
+			}
+			if recovered, err := RecoverSigner(accounts.TextHash(message), signature); err != nil || recovered != address {
+				t.Errorf("expected message signed by %s, got %s (%v)", address, recovered, err)
+			}
+
+			permit := Permit{
+				Token: to, Name: "Token", ChainID: chainId, Spender: to,
+				Value: big.NewInt(1), Nonce: big.NewInt(0), Deadline: big.NewInt(1),
+			}
+			hash, _, err := apitypes.TypedDataAndHash(permit.TypedData(address))
+			if err != nil {
+				t.Fatal(err)
+			}
+			signature, err = signer.SignTypedData(permit.TypedData(address))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if recovered, err := RecoverSigner(hash, signature); err != nil || recovered != address {
+				t.Errorf("expected typed data signed by %s, got %s (%v)", address, recovered, err)
+			}
+
+			other := common.HexToAddress("0x2")
+			if _, err := NewRemoteSigner(httpServer.URL, test.signerType, &other); err == nil {
+				t.Errorf("expected an error with an unknown address")
+			}
+		})
+	}
+}
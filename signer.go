@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 )
 
 type SignerInterface interface {
@@ -15,6 +17,14 @@ type SignerInterface interface {
 	GetAddress() *common.Address
 }
 
+// MessageSigner is implemented by signers also signing EIP-191 messages and EIP-712 typed
+// data, e.g. for permits. Signatures are 65 bytes [R || S || V] with V 27 or 28.
+type MessageSigner interface {
+	SignerInterface
+	SignMessage(message []byte) ([]byte, error)
+	SignTypedData(typedData apitypes.TypedData) ([]byte, error)
+}
+
 type GenericSigner struct {
 	PrivateKey *ecdsa.PrivateKey
 	Address    *common.Address
@@ -41,7 +51,7 @@ func NewSigner(privateKeyHex string) (SignerInterface, error) {
 }
 
 func (s *GenericSigner) SignTx(tx *types.Transaction, chainId *big.Int) (*types.Transaction, error) {
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainId), s.PrivateKey)
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainId), s.PrivateKey)
 	if err != nil {
 		return nil, err
 	}
@@ -52,3 +62,46 @@ func (s *GenericSigner) SignTx(tx *types.Transaction, chainId *big.Int) (*types.
 func (s *GenericSigner) GetAddress() *common.Address {
 	return s.Address
 }
+
+func (s *GenericSigner) SignMessage(message []byte) ([]byte, error) {
+	return signHash(accounts.TextHash(message), s.PrivateKey)
+}
+
+func (s *GenericSigner) SignTypedData(typedData apitypes.TypedData) ([]byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("error hashing typed data: %w", err)
+	}
+
+	return signHash(hash, s.PrivateKey)
+}
+
+func signHash(hash []byte, privateKey *ecdsa.PrivateKey) ([]byte, error) {
+	signature, err := crypto.Sign(hash, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+
+	return signature, nil
+}
+
+// RecoverSigner returns the address signing hash with a 65 bytes [R || S || V] signature,
+// V being 27 or 28 (or 0 or 1).
+func RecoverSigner(hash []byte, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("invalid signature length %d", len(signature))
+	}
+
+	signature = common.CopyBytes(signature)
+	if signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+
+	publicKey, err := crypto.SigToPub(hash, signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
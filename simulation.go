@@ -0,0 +1,67 @@
+package multicall
+
+import (
+	"fmt"
+	"math/big"
+)
+
+const SIMULATION_ERROR_SIGNATURE = "MultiCall__Simulation((bool,bytes,uint256)[])"
+
+// SimulationResult is the outcome of a simulated call.
+type SimulationResult struct {
+	Success    bool
+	ReturnData []byte
+	Decoded    any      // return data decoded with the ReturnTypes of the call, raw when it has none
+	GasUsed    *big.Int // gas used by the call itself, without the batch overhead
+	Err        error    // *CallError of a failed call, or the error decoding its return data
+}
+
+// Simulation holds the per-call results of a simulation and their totals.
+type Simulation struct {
+	Results      []SimulationResult
+	TotalGasUsed *big.Int
+	Succeeded    int
+	Failed       int
+}
+
+// AllSucceeded reports whether every simulated call succeeded.
+func (s Simulation) AllSucceeded() bool {
+	return s.Failed == 0
+}
+
+// newSimulation builds the Simulation of the decoded (success, returnData, gasUsed) tuples
+// of MultiCall__Simulation, one per call.
+func newSimulation(calls CallsInterface, results []any) (Simulation, error) {
+	if len(results) != calls.Len() {
+		return Simulation{}, fmt.Errorf("expected %d simulation results, got %d", calls.Len(), len(results))
+	}
+
+	simulation := Simulation{
+		Results:      make([]SimulationResult, len(results)),
+		TotalGasUsed: new(big.Int),
+	}
+
+	for i, res := range results {
+		tuple := res.([]any)
+		success, _ := tuple[0].(bool)
+		returnData, _ := tuple[1].([]byte)
+		gasUsed, _ := tuple[2].(*big.Int)
+		if gasUsed == nil {
+			gasUsed = new(big.Int)
+		}
+
+		result := SimulationResult{Success: success, ReturnData: returnData, GasUsed: gasUsed}
+		if success {
+			result.Decoded, result.Err = decodeReturnData(calls.GetReturnTypes(i), returnData)
+			simulation.Succeeded++
+		} else {
+			result.Err = newCallError(i, returnData)
+			simulation.Failed++
+		}
+
+		simulation.Results[i] = result
+		simulation.TotalGasUsed.Add(simulation.TotalGasUsed, gasUsed)
+	}
+
+	return simulation, nil
+}
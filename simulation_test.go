@@ -0,0 +1,26 @@
+package multicall
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestNewSimulation(t *testing.T) {
+	calls := NewCalls([]common.Address{common.HexToAddress("0x4444")}, []string{""}, nil, [][]byte{{0x01}}, nil, nil)
+	result := []any{true, []byte{0x02}, big.NewInt(100)}
+
+	simulation, err := newSimulation(calls, []any{result})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !simulation.AllSucceeded() || simulation.TotalGasUsed.Int64() != 100 {
+		t.Errorf("unexpected simulation %+v", simulation)
+	}
+
+	// a revert with more results than calls is an error, not an index out of range
+	if _, err := newSimulation(calls, []any{result, result}); err == nil {
+		t.Errorf("expected an error with 2 results for 1 call")
+	}
+}
@@ -0,0 +1,207 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// storageReaderCode returns the storage slots given as call data, 32 bytes each:
+//
+//	    PUSH1 0
+//	loop:
+//	    JUMPDEST CALLDATASIZE DUP2 LT ISZERO PUSH1 end JUMPI
+//	    DUP1 CALLDATALOAD SLOAD DUP2 MSTORE PUSH1 32 ADD PUSH1 loop JUMP
+//	end:
+//	    JUMPDEST CALLDATASIZE PUSH1 0 RETURN
+var storageReaderCode = common.FromHex("0x60005b3681101560155780355481526020016002565b366000f3")
+
+// ERC-1967 proxy slots.
+var (
+	ERC1967_IMPLEMENTATION_SLOT = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+	ERC1967_ADMIN_SLOT          = common.HexToHash("0xb53127684a568b3173ae13b9f8a6016e243e63b6e8ee1178d6a717850b5d6103")
+	ERC1967_BEACON_SLOT         = common.HexToHash("0xa3f0ad74e5423aebfd80d3ef4346578335a9a72aeaee59ff6cb3582b35133d50")
+)
+
+// errUnexpectedStorage is returned by readStorage when the storage reader returns data of
+// another length than its slots, as when the node ignores the state overrides.
+var errUnexpectedStorage = errors.New("unexpected storage reader result")
+
+// StorageSlot is a storage slot of a contract.
+type StorageSlot struct {
+	Address common.Address
+	Slot    common.Hash
+}
+
+// BatchBackend is implemented by backends sending several JSON-RPC calls in one request.
+type BatchBackend interface {
+	BatchCallContext(ctx context.Context, b []rpc.BatchElem) error
+}
+
+// StorageAt reads the storage slots in one eth_call, replacing the code of each address
+// by a storage reader with a state override. When the node rejects or ignores the state
+// overrides, the slots are read with eth_getStorageAt, in a single batch if the client
+// implements BatchBackend. Any other error of the eth_call is returned. Every slot is read at the same
+// block, and Result.Result is a []common.Hash in the order of the slots.
+func (m *MultiCall) StorageAt(slots []StorageSlot, client Backend, blockNumber *big.Int) Result {
+	return m.StorageAtContext(context.Background(), slots, client, blockNumber)
+}
+
+// StorageAtContext is like StorageAt but runs with the given context.
+func (m *MultiCall) StorageAtContext(
+	ctx context.Context, slots []StorageSlot, client Backend, blockNumber *big.Int,
+) Result {
+	if blockNumber == nil {
+		blockNumberUint64, err := client.BlockNumber(ctx)
+		if err != nil {
+			return Result{Success: false, Error: fmt.Errorf("error getting block number: %w", err)}
+		}
+		blockNumber = new(big.Int).SetUint64(blockNumberUint64)
+	}
+
+	if !m.canOverrideCode(slots) {
+		return getStorageAt(ctx, slots, client, blockNumber)
+	}
+
+	result := m.readStorage(ctx, slots, client, blockNumber)
+	if !result.Success && ctx.Err() == nil &&
+		(errors.Is(result.Error, errUnexpectedStorage) || isOverridesRejected(result.Error)) {
+		return getStorageAt(ctx, slots, client, blockNumber)
+	}
+
+	return result
+}
+
+// canOverrideCode reports whether none of the slots belongs to the multicall contract,
+// whose code cannot be replaced by the storage reader.
+func (m *MultiCall) canOverrideCode(slots []StorageSlot) bool {
+	if m.MultiCallType == DEPLOYLESS || m.ReadAddress == nil {
+		return true
+	}
+
+	for _, slot := range slots {
+		if slot.Address == *m.ReadAddress {
+			return false
+		}
+	}
+
+	return true
+}
+
+// readStorage calls the storage reader of every address with its slots.
+func (m *MultiCall) readStorage(ctx context.Context, slots []StorageSlot, client Backend, blockNumber *big.Int) Result {
+	var calls []Call
+	indexes := make(map[common.Address]int)
+	overrides := m.Overrides
+	for _, slot := range slots {
+		i, ok := indexes[slot.Address]
+		if !ok {
+			i = len(calls)
+			indexes[slot.Address] = i
+			calls = append(calls, NewCall(slot.Address, "", nil, []byte{}, nil, nil))
+			overrides = withCode(overrides, slot.Address, storageReaderCode)
+		}
+		calls[i].CallData = append(calls[i].CallData, slot.Slot.Bytes()...)
+	}
+
+	result := m.WithOverrides(overrides).AggregateStaticContext(ctx, calls, client, blockNumber)
+	if !result.Success {
+		return result
+	}
+
+	returnDatas, ok := result.Result.([]any)
+	if !ok || len(returnDatas) != len(calls) {
+		return Result{
+			Success:  false,
+			Error:    fmt.Errorf("%w %T", errUnexpectedStorage, result.Result),
+			TxOrCall: result.TxOrCall,
+		}
+	}
+
+	values := make([]common.Hash, len(slots))
+	read := make([]int, len(calls))
+	for i, slot := range slots {
+		j := indexes[slot.Address]
+		returnData, ok := returnDatas[j].([]byte)
+		if !ok || len(returnData) != len(calls[j].CallData) {
+			return Result{
+				Success:  false,
+				Error:    fmt.Errorf("%w of %s: %v", errUnexpectedStorage, slot.Address, returnDatas[j]),
+				TxOrCall: result.TxOrCall,
+			}
+		}
+		values[i] = common.BytesToHash(returnData[read[j] : read[j]+32])
+		read[j] += 32
+	}
+
+	return Result{Success: true, Result: values, TxOrCall: result.TxOrCall}
+}
+
+// getStorageAt reads the slots with eth_getStorageAt.
+func getStorageAt(ctx context.Context, slots []StorageSlot, client Backend, blockNumber *big.Int) Result {
+	values := make([]common.Hash, len(slots))
+	batch := make([]rpc.BatchElem, len(slots))
+	for i, slot := range slots {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getStorageAt",
+			Args:   []any{slot.Address, slot.Slot, hexutil.EncodeBig(blockNumber)},
+			Result: &values[i],
+		}
+	}
+
+	txOrCall := TxOrCall{BlockNumber: blockNumber}
+	if batchClient, ok := client.(BatchBackend); ok {
+		if err := batchClient.BatchCallContext(ctx, batch); err != nil {
+			return Result{Success: false, Error: fmt.Errorf("error reading storage: %w", err), TxOrCall: txOrCall}
+		}
+	} else {
+		for i := range batch {
+			batch[i].Error = client.CallContext(ctx, batch[i].Result, batch[i].Method, batch[i].Args...)
+		}
+	}
+
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return Result{
+				Success:  false,
+				Error:    fmt.Errorf("error reading slot %s of %s: %w", slots[i].Slot, slots[i].Address, elem.Error),
+				TxOrCall: txOrCall,
+			}
+		}
+	}
+
+	return Result{Success: true, Result: values, TxOrCall: txOrCall}
+}
+
+// MappingSlot returns the slot of mapping[key] for a mapping at the given slot and a
+// value type key (e.g. common.BytesToHash(address.Bytes()) for an address).
+func MappingSlot(key common.Hash, slot common.Hash) common.Hash {
+	return crypto.Keccak256Hash(key.Bytes(), slot.Bytes())
+}
+
+// BytesMappingSlot returns the slot of mapping[key] for a mapping with string or bytes keys.
+func BytesMappingSlot(key []byte, slot common.Hash) common.Hash {
+	return crypto.Keccak256Hash(key, slot.Bytes())
+}
+
+// ArraySlot returns the slot of array[index] for a dynamic array at the given slot whose
+// elements take elementSlots slots each (1 for value types up to 32 bytes).
+func ArraySlot(slot common.Hash, index *big.Int, elementSlots uint64) common.Hash {
+	offset := new(big.Int).Mul(index, new(big.Int).SetUint64(elementSlots))
+
+	return SlotOffset(crypto.Keccak256Hash(slot.Bytes()), offset)
+}
+
+// SlotOffset returns slot plus offset modulo 2^256, e.g. for the fields of a struct.
+func SlotOffset(slot common.Hash, offset *big.Int) common.Hash {
+	value := new(big.Int).Add(slot.Big(), offset)
+
+	return common.BigToHash(new(big.Int).And(value, math.MaxBig256))
+}
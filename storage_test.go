@@ -0,0 +1,90 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestStorageAt(t *testing.T) {
+	backend := newEVMBackend(t)
+	first, second := common.HexToAddress("0x4444"), common.HexToAddress("0x5555")
+	backend.setCode(first, echoCode)
+	backend.setStorage(first, common.HexToHash("0x1"), common.HexToHash("0xaa"))
+	backend.setStorage(first, ERC1967_IMPLEMENTATION_SLOT, common.BytesToHash(second.Bytes()))
+	backend.setStorage(second, common.HexToHash("0x1"), common.HexToHash("0xbb"))
+
+	slots := []StorageSlot{
+		{Address: first, Slot: common.HexToHash("0x1")},
+		{Address: second, Slot: common.HexToHash("0x1")},
+		{Address: first, Slot: ERC1967_IMPLEMENTATION_SLOT},
+		{Address: second, Slot: common.HexToHash("0x2")},
+	}
+	expected := []common.Hash{common.HexToHash("0xaa"), common.HexToHash("0xbb"), common.BytesToHash(second.Bytes()), {}}
+
+	// the storage reader is used when overrides are supported
+	mcall := &MultiCall{MultiCallType: DEPLOYLESS}
+	if result := mcall.readStorage(context.Background(), slots, backend, nil); !reflect.DeepEqual(result.Result, expected) {
+		t.Errorf("unexpected storage from the reader: %v (%v)", result.Result, result.Error)
+	}
+
+	for _, noOverrides := range []bool{false, true} {
+		backend.noOverrides = noOverrides
+		result := (&MultiCall{MultiCallType: DEPLOYLESS}).StorageAt(slots, backend, nil)
+		if !result.Success || !reflect.DeepEqual(result.Result, expected) {
+			t.Errorf("unexpected storage without overrides %t: %v (%v)", noOverrides, result.Result, result.Error)
+		}
+		if result.TxOrCall.BlockNumber.Uint64() != backend.blockNumber {
+			t.Errorf("expected storage at block %d, got %s", backend.blockNumber, result.TxOrCall.BlockNumber)
+		}
+	}
+
+	// other errors of the storage reader are not hidden by eth_getStorageAt
+	backend.noOverrides = false
+	backend.overridesErr = errors.New("request timed out")
+	result := (&MultiCall{MultiCallType: DEPLOYLESS}).StorageAt(slots, backend, nil)
+	if result.Success || !strings.Contains(result.Error.Error(), "request timed out") {
+		t.Errorf("expected the error of the storage reader call, got %v (%v)", result.Result, result.Error)
+	}
+	backend.overridesErr = nil
+
+	// the code of the contract is restored after the call
+	if code, _ := backend.CodeAt(context.Background(), first, nil); !reflect.DeepEqual(code, echoCode) {
+		t.Errorf("expected the original code, got %x", code)
+	}
+}
+
+func TestStorageSlots(t *testing.T) {
+	if slot := common.BigToHash(new(big.Int).Sub(crypto.Keccak256Hash([]byte("eip1967.proxy.implementation")).Big(), big.NewInt(1))); slot != ERC1967_IMPLEMENTATION_SLOT {
+		t.Errorf("unexpected implementation slot %s", slot)
+	}
+	if slot := common.BigToHash(new(big.Int).Sub(crypto.Keccak256Hash([]byte("eip1967.proxy.admin")).Big(), big.NewInt(1))); slot != ERC1967_ADMIN_SLOT {
+		t.Errorf("unexpected admin slot %s", slot)
+	}
+	if slot := common.BigToHash(new(big.Int).Sub(crypto.Keccak256Hash([]byte("eip1967.proxy.beacon")).Big(), big.NewInt(1))); slot != ERC1967_BEACON_SLOT {
+		t.Errorf("unexpected beacon slot %s", slot)
+	}
+
+	// balanceOf[0x...01] of a mapping at slot 0, as computed by solidity
+	holder := common.HexToAddress("0x1")
+	expected := crypto.Keccak256Hash(common.LeftPadBytes(holder.Bytes(), 32), make([]byte, 32))
+	if slot := MappingSlot(common.BytesToHash(holder.Bytes()), common.Hash{}); slot != expected {
+		t.Errorf("expected mapping slot %s, got %s", expected, slot)
+	}
+
+	base := crypto.Keccak256Hash(common.HexToHash("0x3").Bytes())
+	expected = common.BigToHash(new(big.Int).Add(base.Big(), big.NewInt(4)))
+	if slot := ArraySlot(common.HexToHash("0x3"), big.NewInt(2), 2); slot != expected {
+		t.Errorf("expected array slot %s, got %s", expected, slot)
+	}
+
+	if slot := SlotOffset(common.MaxHash, big.NewInt(2)); slot != common.HexToHash("0x1") {
+		t.Errorf("expected wrapped slot 0x1, got %s", slot)
+	}
+}
@@ -0,0 +1,206 @@
+package multicall_test
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/omnes-tech/abi"
+	"github.com/omnes-tech/multicall"
+	"github.com/omnes-tech/multicall/erc20"
+	"github.com/omnes-tech/multicall/nft"
+)
+
+// mockCode returns the return data stored for its call data and reverts without any. The
+// length of the return data of call data c is at slot keccak256(c) and its words at the
+// following slots:
+//
+//	CALLDATASIZE PUSH0 PUSH0 CALLDATACOPY CALLDATASIZE PUSH0 KECCAK256 DUP1 SLOAD
+//	DUP1 ISZERO PUSH1 revert JUMPI PUSH0
+//	loop: JUMPDEST DUP2 DUP2 LT ISZERO PUSH1 end JUMPI
+//	      DUP1 PUSH1 5 SHR DUP4 ADD PUSH1 1 ADD SLOAD DUP2 MSTORE PUSH1 0x20 ADD PUSH1 loop JUMP
+//	end: JUMPDEST POP PUSH0 RETURN
+//	revert: JUMPDEST PUSH0 PUSH0 REVERT
+var mockCode = common.FromHex(
+	"0x365f5f37365f2080548015602d575f5b818110156029578060051c83016001015481526020016" +
+		"00f565b505ff35b5f5ffd",
+)
+
+// mockContract deploys mockCode at address and stubs its return data by call data.
+type mockContract struct {
+	t       *testing.T
+	backend *multicall.EVMBackend
+	address common.Address
+}
+
+func newMockContract(t *testing.T, backend *multicall.EVMBackend, address common.Address) *mockContract {
+	backend.SetCode(address, mockCode)
+
+	return &mockContract{t: t, backend: backend, address: address}
+}
+
+// returns stubs the call to funcSignature with args to return the given values encoded as
+// returnTypes.
+func (m *mockContract) returns(funcSignature string, args []any, returnTypes []string, values ...any) {
+	m.t.Helper()
+
+	callData, err := abi.EncodeWithSignature(funcSignature, args...)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	returnData, err := abi.Encode(returnTypes, values...)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	m.returnsData(callData, returnData)
+}
+
+// returnsData stubs the call with callData to return returnData as is.
+func (m *mockContract) returnsData(callData []byte, returnData []byte) {
+	key := new(big.Int).SetBytes(crypto.Keccak256(callData))
+	m.backend.SetStorage(m.address, common.BigToHash(key), common.BigToHash(big.NewInt(int64(len(returnData)))))
+	for i := 0; i*32 < len(returnData); i++ {
+		slot := new(big.Int).Add(key, big.NewInt(int64(i+1)))
+		word := common.RightPadBytes(returnData[i*32:min(len(returnData), (i+1)*32)], 32)
+		m.backend.SetStorage(m.address, common.BigToHash(slot), common.BytesToHash(word))
+	}
+}
+
+func TestERC20(t *testing.T) {
+	backend := multicall.NewEVMBackend(t)
+	mcall := &multicall.MultiCall{MultiCallType: multicall.DEPLOYLESS}
+
+	dai := newMockContract(t, backend, common.HexToAddress("0x6b17"))
+	dai.returns("name()", nil, []string{"string"}, "Dai Stablecoin")
+	dai.returns("symbol()", nil, []string{"string"}, "DAI")
+	dai.returns("decimals()", nil, []string{"uint8"}, big.NewInt(18))
+	dai.returns("totalSupply()", nil, []string{"uint256"}, big.NewInt(1000))
+
+	// MKR returns its name and symbol as bytes32
+	mkr := newMockContract(t, backend, common.HexToAddress("0x9f8f"))
+	mkr.returns("name()", nil, []string{"bytes32"}, common.RightPadBytes([]byte("Maker"), 32))
+	mkr.returns("symbol()", nil, []string{"bytes32"}, common.RightPadBytes([]byte("MKR"), 32))
+	mkr.returns("decimals()", nil, []string{"uint256"}, big.NewInt(18))
+	mkr.returns("totalSupply()", nil, []string{"uint256"}, big.NewInt(5))
+
+	// an old token without name and decimals
+	old := newMockContract(t, backend, common.HexToAddress("0x01d0"))
+	old.returns("symbol()", nil, []string{"string"}, "OLD")
+	old.returns("totalSupply()", nil, []string{"uint256"}, big.NewInt(7))
+
+	tokens, _, err := erc20.Metadata(mcall, []common.Address{dai.address, mkr.address, old.address}, backend, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []erc20.Token{
+		{Address: dai.address, Name: "Dai Stablecoin", Symbol: "DAI", Decimals: 18, TotalSupply: big.NewInt(1000)},
+		{Address: mkr.address, Name: "Maker", Symbol: "MKR", Decimals: 18, TotalSupply: big.NewInt(5)},
+		{Address: old.address, Symbol: "OLD", TotalSupply: big.NewInt(7), Missing: []string{"name()", "decimals()"}},
+	}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("expected tokens %+v, got %+v", expected, tokens)
+	}
+
+	holders := []common.Address{common.HexToAddress("0x1111"), common.HexToAddress("0x2222")}
+	dai.returns("balanceOf(address)", []any{&holders[0]}, []string{"uint256"}, big.NewInt(10))
+	dai.returns("balanceOf(address)", []any{&holders[1]}, []string{"uint256"}, big.NewInt(20))
+	mkr.returns("balanceOf(address)", []any{&holders[0]}, []string{"uint256"}, big.NewInt(3))
+
+	balances, _, err := erc20.Balances(mcall, []common.Address{dai.address, mkr.address}, holders, backend, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedBalances := [][]*big.Int{{big.NewInt(10), big.NewInt(20)}, {big.NewInt(3), nil}}
+	if !reflect.DeepEqual(balances, expectedBalances) {
+		t.Errorf("expected balances %v, got %v", expectedBalances, balances)
+	}
+}
+
+func TestNFT(t *testing.T) {
+	backend := multicall.NewEVMBackend(t)
+	mcall := &multicall.MultiCall{MultiCallType: multicall.DEPLOYLESS}
+	opts := multicall.ChunkOptions{MaxCalls: 1}
+	owner, operator := common.HexToAddress("0x1111"), common.HexToAddress("0x2222")
+
+	erc721 := newMockContract(t, backend, common.HexToAddress("0xbc4c"))
+	erc721.returns("ownerOf(uint256)", []any{big.NewInt(1)}, []string{"address"}, &owner)
+	erc721.returns("tokenURI(uint256)", []any{big.NewInt(1)}, []string{"string"}, "ipfs://token/1")
+	erc721.returns("balanceOf(address)", []any{&owner}, []string{"uint256"}, big.NewInt(1))
+	erc721.returns("isApprovedForAll(address,address)", []any{&owner, &operator}, []string{"bool"}, true)
+	erc721InterfaceID := multicall.ERC721_INTERFACE_ID
+	erc721.returns("supportsInterface(bytes4)", []any{erc721InterfaceID[:]}, []string{"bool"}, true)
+	invalidInterfaceID := multicall.INVALID_INTERFACE_ID
+	erc721.returns("supportsInterface(bytes4)", []any{invalidInterfaceID[:]}, []string{"bool"}, false)
+
+	erc1155 := newMockContract(t, backend, common.HexToAddress("0x76be"))
+	erc1155.returns("uri(uint256)", []any{big.NewInt(5)}, []string{"string"}, "https://token/{id}.json")
+	erc1155.returns(
+		"balanceOfBatch(address[],uint256[])", []any{[]any{&owner, &operator}, []any{big.NewInt(5), big.NewInt(5)}},
+		[]string{"uint256[]"}, []any{big.NewInt(4), big.NewInt(0)},
+	)
+
+	minted := nft.NFT{Collection: erc721.address, ID: big.NewInt(1)}
+	burned := nft.NFT{Collection: erc721.address, ID: big.NewInt(2)}
+	owners, _, err := nft.OwnersOf(mcall, []nft.NFT{minted, burned}, backend, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outcome := owners[minted.Key()]; !outcome.Success || outcome.Value != owner {
+		t.Errorf("expected owner %s, got %+v", owner, outcome)
+	}
+	var callError *multicall.CallError
+	if outcome := owners[burned.Key()]; outcome.Success || !errors.As(outcome.Err, &callError) || callError.Index != 1 {
+		t.Errorf("expected call 1 of the burned token to fail, got %+v", outcome)
+	}
+
+	tokenURIs, _, err := nft.TokenURIs(mcall, []nft.NFT{minted}, backend, nil, opts)
+	if err != nil || tokenURIs[minted.Key()].Value != "ipfs://token/1" {
+		t.Errorf("unexpected token URIs %+v (%v)", tokenURIs, err)
+	}
+
+	balances, _, err := nft.BalancesOf(mcall, []common.Address{erc721.address}, []common.Address{owner}, backend, nil, opts)
+	if err != nil || balances[erc721.address][owner].Value.Int64() != 1 {
+		t.Errorf("unexpected balances %+v (%v)", balances, err)
+	}
+
+	approval := nft.Approval{Owner: owner, Operator: operator}
+	approvals, _, err := nft.ApprovalsForAll(mcall, []common.Address{erc721.address}, []nft.Approval{approval}, backend, nil, opts)
+	if err != nil || !approvals[erc721.address][approval].Value {
+		t.Errorf("unexpected approvals %+v (%v)", approvals, err)
+	}
+
+	supports, _, err := nft.SupportsInterfaces(
+		mcall, []common.Address{erc721.address, erc1155.address}, [][4]byte{erc721InterfaceID, invalidInterfaceID},
+		backend, nil, opts,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !supports[erc721.address][erc721InterfaceID].Value || supports[erc721.address][invalidInterfaceID].Value {
+		t.Errorf("unexpected interfaces of %s: %+v", erc721.address, supports[erc721.address])
+	}
+	if supports[erc1155.address][erc721InterfaceID].Success {
+		t.Errorf("expected supportsInterface of %s to fail", erc1155.address)
+	}
+
+	token := nft.NFT{Collection: erc1155.address, ID: big.NewInt(5)}
+	uris, _, err := nft.URIs(mcall, []nft.NFT{token}, backend, nil, opts)
+	expectedURI := "https://token/0000000000000000000000000000000000000000000000000000000000000005.json"
+	if err != nil || uris[token.Key()].Value != expectedURI {
+		t.Errorf("expected URI %s, got %+v (%v)", expectedURI, uris, err)
+	}
+
+	holdings, _, err := nft.BalancesOfBatch(
+		mcall, []nft.Holding{{Owner: owner, NFT: token}, {Owner: operator, NFT: token}}, backend, nil, opts,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if holdings[token.Key()][owner].Value.Int64() != 4 || !holdings[token.Key()][operator].Success ||
+		holdings[token.Key()][operator].Value.Sign() != 0 {
+		t.Errorf("unexpected holdings %+v", holdings[token.Key()])
+	}
+}
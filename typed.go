@@ -0,0 +1,225 @@
+package multicall
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Decoder decodes the return data of a call into a T.
+type Decoder[T any] func(returnData []byte) (T, error)
+
+// TypedCall is a call whose return data is decoded into a T instead of nested []any.
+type TypedCall[T any] struct {
+	Call    Call
+	Decoder Decoder[T]
+}
+
+// Outcome is the typed result of a call that is allowed to fail.
+type Outcome[T any] struct {
+	Success bool
+	Value   T
+	Err     error
+}
+
+func NewTypedCall[T any](
+	target common.Address, funcSignature string, args []any, decoder Decoder[T],
+) TypedCall[T] {
+	return TypedCall[T]{
+		Call:    NewCall(target, funcSignature, args, nil, nil, nil),
+		Decoder: decoder,
+	}
+}
+
+// DecodeAs returns a Decoder decoding the return data with the given return types. A
+// single return value is converted to T, several ones are kept as []any. T must match
+// the type produced by the abi package (e.g. *big.Int for integers, bool, string, []byte),
+// except for common.Address, which is converted from its hex string.
+func DecodeAs[T any](returnTypes ...string) Decoder[T] {
+	return func(returnData []byte) (T, error) {
+		var zero T
+		decoded, err := safeDecode(returnTypes, returnData)
+		if err != nil {
+			return zero, err
+		}
+
+		var value any = decoded
+		if len(decoded) == 1 {
+			value = decoded[0]
+		}
+		if _, ok := any(zero).(common.Address); ok {
+			if hexAddress, ok := value.(string); ok {
+				value = common.HexToAddress(hexAddress)
+			}
+		}
+
+		typedValue, ok := value.(T)
+		if !ok {
+			return zero, fmt.Errorf("cannot convert %T to %T", value, zero)
+		}
+
+		return typedValue, nil
+	}
+}
+
+// Aggregate runs the calls with AggregateStatic and decodes each return value. It fails
+// when any call reverts or cannot be decoded.
+func Aggregate[T any](
+	m *MultiCall, calls []TypedCall[T], client Backend, blockNumber *big.Int,
+) ([]T, TxOrCall, error) {
+	return AggregateContext(context.Background(), m, calls, client, blockNumber)
+}
+
+// AggregateContext is like Aggregate but runs with the given context.
+func AggregateContext[T any](
+	ctx context.Context, m *MultiCall, calls []TypedCall[T], client Backend, blockNumber *big.Int,
+) ([]T, TxOrCall, error) {
+	rawCalls := make([]Call, len(calls))
+	for i, typedCall := range calls {
+		rawCalls[i] = typedCall.Call
+		rawCalls[i].ReturnTypes = nil
+	}
+
+	result := m.AggregateStaticContext(ctx, rawCalls, client, blockNumber)
+	if !result.Success {
+		return nil, result.TxOrCall, resultError(result)
+	}
+
+	values, err := decodeTyped(calls, result.Result)
+	if err != nil {
+		return nil, result.TxOrCall, err
+	}
+
+	return values, result.TxOrCall, nil
+}
+
+// TryAggregate runs the calls with TryAggregateStatic3, allowing every call to fail,
+// and returns one Outcome per call. Reverts and decoding errors are reported in the
+// Outcome of the call instead of failing the batch.
+func TryAggregate[T any](
+	m *MultiCall, calls []TypedCall[T], client Backend, blockNumber *big.Int,
+) ([]Outcome[T], TxOrCall, error) {
+	return TryAggregateContext(context.Background(), m, calls, client, blockNumber)
+}
+
+// TryAggregateContext is like TryAggregate but runs with the given context.
+func TryAggregateContext[T any](
+	ctx context.Context, m *MultiCall, calls []TypedCall[T], client Backend, blockNumber *big.Int,
+) ([]Outcome[T], TxOrCall, error) {
+	rawCalls := make([]CallWithFailure, len(calls))
+	for i, typedCall := range calls {
+		rawCalls[i] = CallWithFailure{Call: typedCall.Call}
+		rawCalls[i].ReturnTypes = nil
+	}
+
+	result := m.TryAggregateStatic3Context(ctx, rawCalls, client, blockNumber)
+
+	return typedOutcomesResult(calls, result)
+}
+
+// TryAggregateChunked is like TryAggregate but splits the calls into several aggregate
+// calls, executed at the same block, according to the given options.
+func TryAggregateChunked[T any](
+	m *MultiCall, calls []TypedCall[T], client Backend, blockNumber *big.Int, opts ChunkOptions,
+) ([]Outcome[T], TxOrCall, error) {
+	return TryAggregateChunkedContext(context.Background(), m, calls, client, blockNumber, opts)
+}
+
+// TryAggregateChunkedContext is like TryAggregateChunked but runs with the given context.
+func TryAggregateChunkedContext[T any](
+	ctx context.Context, m *MultiCall, calls []TypedCall[T], client Backend, blockNumber *big.Int, opts ChunkOptions,
+) ([]Outcome[T], TxOrCall, error) {
+	rawCalls := make([]CallWithFailure, len(calls))
+	for i, typedCall := range calls {
+		rawCalls[i] = CallWithFailure{Call: typedCall.Call}
+		rawCalls[i].ReturnTypes = nil
+	}
+
+	result := m.TryAggregateStatic3ChunkedContext(ctx, rawCalls, client, blockNumber, opts)
+
+	return typedOutcomesResult(calls, result)
+}
+
+func typedOutcomesResult[T any](calls []TypedCall[T], result Result) ([]Outcome[T], TxOrCall, error) {
+	if !result.Success {
+		return nil, result.TxOrCall, resultError(result)
+	}
+
+	outcomes, err := decodeTypedOutcomes(calls, result.Result)
+	if err != nil {
+		return nil, result.TxOrCall, err
+	}
+
+	return outcomes, result.TxOrCall, nil
+}
+
+// decodeTyped decodes the raw return data of aggregated calls.
+func decodeTyped[T any](calls []TypedCall[T], result any) ([]T, error) {
+	results, ok := result.([]any)
+	if !ok || len(results) != len(calls) {
+		return nil, fmt.Errorf("unexpected result %T for %d calls", result, len(calls))
+	}
+
+	values := make([]T, len(calls))
+	for i, res := range results {
+		returnData, ok := res.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("unexpected return data %T for call %d", res, i)
+		}
+
+		value, err := calls[i].Decoder(returnData)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding call %d: %w", i, err)
+		}
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+// decodeTypedOutcomes decodes the (success, returnData) tuples of aggregated calls.
+func decodeTypedOutcomes[T any](calls []TypedCall[T], result any) ([]Outcome[T], error) {
+	results, ok := result.([]any)
+	if !ok || len(results) != len(calls) {
+		return nil, fmt.Errorf("unexpected result %T for %d calls", result, len(calls))
+	}
+
+	outcomes := make([]Outcome[T], len(calls))
+	for i, res := range results {
+		tuple, ok := res.([]any)
+		if !ok || len(tuple) != 2 {
+			return nil, fmt.Errorf("unexpected result %T for call %d", res, i)
+		}
+		if callError, ok := tuple[1].(*CallError); ok {
+			outcomes[i].Err = callError
+			continue
+		}
+		returnData, ok := tuple[1].([]byte)
+		if !ok {
+			return nil, fmt.Errorf("unexpected return data %T for call %d", tuple[1], i)
+		}
+		if success, _ := tuple[0].(bool); !success {
+			outcomes[i].Err = newCallError(i, returnData)
+			continue
+		}
+
+		value, err := calls[i].Decoder(returnData)
+		if err != nil {
+			outcomes[i].Err = fmt.Errorf("error decoding call %d: %w", i, err)
+			continue
+		}
+		outcomes[i] = Outcome[T]{Success: true, Value: value}
+	}
+
+	return outcomes, nil
+}
+
+func resultError(result Result) error {
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return fmt.Errorf("call failed")
+}
@@ -0,0 +1,129 @@
+package multicall
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/omnes-tech/abi"
+)
+
+func encodeUint256(t *testing.T, value int64) []byte {
+	t.Helper()
+
+	encoded, err := abi.Encode([]string{"uint256"}, big.NewInt(value))
+	if err != nil {
+		t.Fatalf("error encoding value: %v", err)
+	}
+
+	return encoded
+}
+
+func TestDecodeAs(t *testing.T) {
+	value, err := DecodeAs[*big.Int]("uint256")(encodeUint256(t, 42))
+	if err != nil {
+		t.Fatalf("error decoding uint256: %v", err)
+	}
+	if value.Int64() != 42 {
+		t.Errorf("expected 42, got %s", value)
+	}
+
+	owner := common.HexToAddress("0x4444")
+	encodedOwner, err := abi.Encode([]string{"address"}, &owner)
+	if err != nil {
+		t.Fatalf("error encoding address: %v", err)
+	}
+	address, err := DecodeAs[common.Address]("address")(encodedOwner)
+	if err != nil {
+		t.Fatalf("error decoding address: %v", err)
+	}
+	if address != owner {
+		t.Errorf("expected %s, got %s", owner, address)
+	}
+
+	if _, err := DecodeAs[bool]("uint256")(encodeUint256(t, 1)); err == nil {
+		t.Errorf("expected conversion error")
+	}
+	if _, err := DecodeAs[*big.Int]("uint256")([]byte{0x01}); err == nil {
+		t.Errorf("expected error decoding short data")
+	}
+}
+
+func TestDecodeTypedOutcomes(t *testing.T) {
+	target := common.HexToAddress("0x4444")
+	calls := []TypedCall[*big.Int]{
+		NewTypedCall(target, "a()", nil, DecodeAs[*big.Int]("uint256")),
+		NewTypedCall(target, "b()", nil, DecodeAs[*big.Int]("uint256")),
+		NewTypedCall(target, "c()", nil, DecodeAs[*big.Int]("uint256")),
+	}
+
+	outcomes, err := decodeTypedOutcomes(calls, []any{
+		[]any{true, encodeUint256(t, 7)},
+		[]any{false, []byte{0xde, 0xad}},
+		[]any{true, []byte{0x01}},
+	})
+	if err != nil {
+		t.Fatalf("error decoding outcomes: %v", err)
+	}
+
+	if !outcomes[0].Success || outcomes[0].Value.Int64() != 7 {
+		t.Errorf("expected successful outcome with value 7, got %+v", outcomes[0])
+	}
+	if outcomes[1].Success || outcomes[1].Err == nil {
+		t.Errorf("expected failed outcome, got %+v", outcomes[1])
+	}
+	if outcomes[2].Success || outcomes[2].Err == nil {
+		t.Errorf("expected decoding error, got %+v", outcomes[2])
+	}
+}
+
+func TestDecodeAggregateCallsResultKeepsRawData(t *testing.T) {
+	calls := newRawCalls([]byte{0x01}, []byte{0x02})
+	calls[1].ReturnTypes = []string{"uint256"}
+
+	decoded, err := decodeAggregateCallsResult([]any{[]byte{0xab}, encodeUint256(t, 3)}, calls)
+	if err != nil {
+		t.Fatalf("error decoding results: %v", err)
+	}
+
+	if raw, ok := decoded[0].([]byte); !ok || raw[0] != 0xab {
+		t.Errorf("expected raw return data, got %v", decoded[0])
+	}
+
+	values, err := decodeTyped(
+		[]TypedCall[[]byte]{{Decoder: func(b []byte) ([]byte, error) { return b, nil }}},
+		decoded[:1],
+	)
+	if err != nil || values[0][0] != 0xab {
+		t.Errorf("expected typed raw return data, got %v (%v)", values, err)
+	}
+}
+
+func TestTryAggregateChunked(t *testing.T) {
+	backend := newEVMBackend(t)
+	echo, reverting := common.HexToAddress("0x4444"), common.HexToAddress("0x5555")
+	backend.setCode(echo, echoCode)
+	backend.setCode(reverting, revertCode)
+	mcall := &MultiCall{MultiCallType: DEPLOYLESS}
+
+	raw := func(returnData []byte) ([]byte, error) { return returnData, nil }
+	calls := []TypedCall[[]byte]{
+		{Call: NewCall(echo, "", nil, []byte{0x01}, nil, nil), Decoder: raw},
+		{Call: NewCall(reverting, "", nil, []byte{0x02}, nil, nil), Decoder: raw},
+		{Call: NewCall(echo, "", nil, []byte{0x03}, nil, nil), Decoder: raw},
+	}
+
+	outcomes, _, err := TryAggregateChunked(mcall, calls, backend, nil, ChunkOptions{MaxCalls: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !outcomes[0].Success || !outcomes[2].Success || !bytes.Equal(outcomes[2].Value, []byte{0x03}) {
+		t.Errorf("unexpected outcomes %+v", outcomes)
+	}
+	var callError *CallError
+	if outcomes[1].Success || !errors.As(outcomes[1].Err, &callError) || callError.Index != 1 {
+		t.Errorf("expected failed call 1, got %+v", outcomes[1])
+	}
+}
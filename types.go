@@ -3,10 +3,12 @@ package multicall
 import (
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
 	"github.com/omnes-tech/abi"
 )
 
@@ -18,6 +20,68 @@ const (
 	DEPLOYLESS
 )
 
+type TxType uint8
+
+const (
+	AUTO_TX = iota // dynamic fee when the chain has a base fee, legacy otherwise
+	LEGACY_TX
+	DYNAMIC_FEE_TX
+)
+
+// TxOptions customizes the transactions sent by the write methods. A nil *TxOptions
+// uses the defaults, and so does the zero value of each field.
+type TxOptions struct {
+	Type TxType
+
+	GasLimit           uint64   // fixed gas limit, estimated when zero
+	GasLimitMultiplier float64  // applied to the estimated gas limit, ignored with GasLimit
+	GasPrice           *big.Int // gas price of legacy transactions, suggested when nil
+	GasFeeCap          *big.Int // EIP-1559 fee cap per gas, tip plus twice the base fee when nil
+	GasTipCap          *big.Int // EIP-1559 tip per gas, suggested when nil
+	Nonce              *uint64  // pending nonce of the signer, or allocated by NonceManager, when nil
+	Value              *big.Int // overrides the summed value of the calls
+	AccessList         types.AccessList
+
+	// From is the sender used to build unsigned transactions when no signer is configured.
+	From *common.Address
+
+	// NonceManager allocates the nonce locally when Nonce is nil, and releases it when the
+	// transaction is not sent, e.g. with NoSend. Share it between the writes sent
+	// concurrently from the same signer. The Build methods ignore it, as nothing would
+	// release the nonce of a built transaction that is never broadcast.
+	NonceManager *NonceManager
+
+	// NoSend builds, signs and simulates the transaction without broadcasting it.
+	NoSend bool
+
+	// Replacement re-sends the transaction with bumped fees while it is not mined.
+	Replacement *ReplacementPolicy
+}
+
+// ReplacementPolicy re-signs a pending transaction with the same nonce and higher fees
+// when it is not mined in time. Every replacement is kept pending, and the receipt of
+// whichever transaction lands first is returned. Either Timeout or Blocks must be set.
+type ReplacementPolicy struct {
+	Timeout      time.Duration // time to wait for each transaction, ignored with Blocks
+	Blocks       uint64        // number of blocks to wait for each transaction
+	BumpPercent  float64       // fee increase of each replacement, raised to the node minimum
+	MaxAttempts  int           // maximum number of replacements
+	MaxGasPrice  *big.Int      // replacements stop once the gas price or fee cap would exceed it
+	PollInterval time.Duration // DEFAULT_RECEIPT_POLL_INTERVAL if zero
+}
+
+// StateOverride replaces the balance, nonce, code or storage of accounts during a call.
+type StateOverride = map[common.Address]gethclient.OverrideAccount
+
+// BlockOverrides replaces fields of the block a call is executed in.
+type BlockOverrides = gethclient.BlockOverrides
+
+// CallOverrides are the state and block overrides sent with every eth_call of a read.
+type CallOverrides struct {
+	State StateOverride
+	Block *BlockOverrides
+}
+
 type TxOrCall struct {
 	From        common.Address
 	To          *common.Address
@@ -31,6 +95,9 @@ type TxOrCall struct {
 	BlockNumber *big.Int
 
 	AccessList types.AccessList
+
+	// ReplacedHashes are the hashes of the transactions replaced by this one.
+	ReplacedHashes []common.Hash
 }
 
 func (t *TxOrCall) String() string {
@@ -39,31 +106,43 @@ func (t *TxOrCall) String() string {
 	To: %s, 
 	Gas: %d, 
 	GasPrice: %s, 
+	GasFeeCap: %s, 
+	GasTipCap: %s, 
 	Value: %s, 
 	Data: %s,
 	Nonce: %d,
 	BlockNumber: %s,
 	AccessList: %v,
+	ReplacedHashes: %v,
 }
 `,
 		t.From.Hex(),
 		t.To.Hex(),
 		t.Gas,
 		t.GasPrice.String(),
+		t.GasFeeCap.String(),
+		t.GasTipCap.String(),
 		t.Value.String(),
 		common.Bytes2Hex(t.Data),
 		t.Nonce,
 		t.BlockNumber.String(),
 		t.AccessList,
+		t.ReplacedHashes,
 	)
 }
 
 func FromTxToTxOrCall(tx *types.Transaction, from common.Address, blockNumber *big.Int) TxOrCall {
+	if tx == nil {
+		return TxOrCall{From: from, BlockNumber: blockNumber}
+	}
+
 	return TxOrCall{
 		From:        from,
 		To:          tx.To(),
 		Gas:         tx.Gas(),
 		GasPrice:    tx.GasPrice(),
+		GasFeeCap:   tx.GasFeeCap(),
+		GasTipCap:   tx.GasTipCap(),
 		Value:       tx.Value(),
 		Data:        tx.Data(),
 		Nonce:       tx.Nonce(),
@@ -78,6 +157,8 @@ func FromCallToTxOrCall(call *ethereum.CallMsg, blockNumber *big.Int) TxOrCall {
 		To:          call.To,
 		Gas:         call.Gas,
 		GasPrice:    call.GasPrice,
+		GasFeeCap:   call.GasFeeCap,
+		GasTipCap:   call.GasTipCap,
 		Value:       call.Value,
 		Data:        call.Data,
 		BlockNumber: blockNumber,
@@ -266,6 +347,53 @@ func setParameters(
 	return args, callData, returnTypes, value
 }
 
+// encodeCallData returns the call data of the i-th call, encoding it from its function
+// signature and arguments when no raw call data was given.
+func encodeCallData(calls CallsInterface, i int) ([]byte, error) {
+	callData := calls.GetCallData(i)
+	if callData != nil {
+		return callData, nil
+	}
+
+	if calls.GetArgs(i) != nil || len(calls.GetArgs(i)) > 0 {
+		return abi.EncodeWithSignature(calls.GetFuncSignature(i), calls.GetArgs(i)...)
+	}
+
+	return abi.EncodeWithSignature(calls.GetFuncSignature(i))
+}
+
+// decodeReturnData decodes the return data of a call with its return types. Calls
+// without return types keep their raw return data.
+func decodeReturnData(returnTypes []string, returnData []byte) (any, error) {
+	if len(returnTypes) == 0 {
+		return returnData, nil
+	}
+
+	return safeDecode(returnTypes, returnData)
+}
+
+// safeDecode is abi.Decode turning the panics raised on malformed data into errors.
+func safeDecode(typeStrs []string, data []byte) (decoded []any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			decoded, err = nil, fmt.Errorf("error decoding %v from 0x%s: %v", typeStrs, common.Bytes2Hex(data), r)
+		}
+	}()
+
+	return abi.Decode(typeStrs, data)
+}
+
+// safeDecodeWithSignature is abi.DecodeWithSignature turning panics into errors.
+func safeDecodeWithSignature(signature string, data []byte) (decoded []any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			decoded, err = nil, fmt.Errorf("error decoding %s from 0x%s: %v", signature, common.Bytes2Hex(data), r)
+		}
+	}()
+
+	return abi.DecodeWithSignature(signature, data)
+}
+
 func (c Calls) GetTarget(i int) *common.Address {
 	return &c[i].Target
 }
@@ -301,17 +429,9 @@ func (c Calls) ToArray(withValue bool, isMultiCall3Type bool) ([]any, *big.Int,
 		var args []any
 		args = append(args, c.GetTarget(i))
 
-		callData := c.GetCallData(i)
-		if callData == nil {
-			var err error
-			if c.GetArgs(i) != nil || len(c.GetArgs(i)) > 0 {
-				callData, err = abi.EncodeWithSignature(c.GetFuncSignature(i), c.GetArgs(i)...)
-			} else {
-				callData, err = abi.EncodeWithSignature(c.GetFuncSignature(i))
-			}
-			if err != nil {
-				return nil, nil, err
-			}
+		callData, err := encodeCallData(c, i)
+		if err != nil {
+			return nil, nil, err
 		}
 		args = append(args, callData)
 
@@ -372,17 +492,9 @@ func (c CallsWithFailure) ToArray(withValue bool, isMultiCall3Type bool) ([]any,
 	for i := 0; i < c.Len(); i++ {
 		var args []any
 
-		callData := c.GetCallData(i)
-		if callData == nil {
-			var err error
-			if c.GetArgs(i) != nil || len(c.GetArgs(i)) > 0 {
-				callData, err = abi.EncodeWithSignature(c.GetFuncSignature(i), c.GetArgs(i)...)
-			} else {
-				callData, err = abi.EncodeWithSignature(c.GetFuncSignature(i))
-			}
-			if err != nil {
-				return nil, nil, err
-			}
+		callData, err := encodeCallData(c, i)
+		if err != nil {
+			return nil, nil, err
 		}
 
 		if isMultiCall3Type {